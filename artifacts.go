@@ -0,0 +1,219 @@
+package solc
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+)
+
+// ArtifactFormat is the file format of artifacts written by [WriteArtifacts].
+type ArtifactFormat string
+
+const (
+	// ArtifactFormatFoundry writes artifacts to "<dir>/<File.sol>/<Contract>.json"
+	// in the format of Foundry's "out/" directory.
+	ArtifactFormatFoundry ArtifactFormat = "foundry"
+
+	// ArtifactFormatHardhat writes artifacts to
+	// "<dir>/<path/to/File.sol>/<Contract>.json" in Hardhat's
+	// "hh-sol-artifact-1" format.
+	ArtifactFormatHardhat ArtifactFormat = "hardhat"
+)
+
+// WriteArtifacts writes an artifact file for each of the given contracts, keyed
+// by source file and contract name as returned by [Compiler.Compile], to the
+// given directory. Artifacts only contain outputs that were selected in the
+// output selection. The contracts of the console.sol source added by the
+// compiler are skipped.
+//
+// An error is returned before any file is written if the artifacts of multiple
+// contracts would have the same path, e.g. in the Foundry format for contracts
+// with the same name in source files with the same base name.
+func WriteArtifacts(dir string, format ArtifactFormat, contracts map[string]map[string]Contract) error {
+	artifacts := make(map[string]any)  // by path
+	written := make(map[string]string) // fully qualified contract name by path
+	for _, file := range slices.Sorted(maps.Keys(contracts)) {
+		if file == consoleFile {
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(contracts[file])) {
+			contract := contracts[file][name]
+
+			var (
+				path     string
+				artifact any
+				err      error
+			)
+			switch format {
+			case ArtifactFormatFoundry:
+				path = filepath.Join(dir, filepath.Base(file), name+".json")
+				artifact, err = newFoundryArtifact(contract)
+			case ArtifactFormatHardhat:
+				path = filepath.Join(dir, filepath.FromSlash(file), name+".json")
+				artifact = newHardhatArtifact(file, name, contract)
+			default:
+				return fmt.Errorf("solc: unknown artifact format %q", format)
+			}
+			if err != nil {
+				return fmt.Errorf("solc: %s:%s: %w", file, name, err)
+			}
+			if other, ok := written[path]; ok {
+				return fmt.Errorf("solc: artifacts of %s and %s:%s have the same path %s", other, file, name, path)
+			}
+			written[path] = file + ":" + name
+			artifacts[path] = artifact
+		}
+	}
+
+	for path, artifact := range artifacts {
+		data, err := json.MarshalIndent(artifact, "", "  ")
+		if err != nil {
+			return err
+		}
+		if err := os.MkdirAll(filepath.Dir(path), perm); err != nil {
+			return err
+		}
+		if err := os.WriteFile(path, append(data, '\n'), 0o644); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// WriteBinABI writes the classic output files of solc's --bin, --abi and
+// --bin-runtime flags for each of the given contracts, keyed by source file and
+// contract name as returned by [Compiler.Compile], to the given directory:
+// "<Contract>.bin" containing the hex encoded bytecode, "<Contract>.abi"
+// containing the ABI and, if runtime is set, "<Contract>.bin-runtime"
+// containing the hex encoded deployed bytecode. The contracts of the
+// console.sol source added by the compiler are skipped.
+//
+// An error is returned if multiple source files contain a contract with the
+// same name, as their files would collide.
+func WriteBinABI(dir string, contracts map[string]map[string]Contract, runtime bool) error {
+	files := make(map[string]string) // source file by contract name
+	for _, file := range slices.Sorted(maps.Keys(contracts)) {
+		if file == consoleFile {
+			continue
+		}
+		for name := range contracts[file] {
+			if other, ok := files[name]; ok {
+				return fmt.Errorf("solc: contract %s is defined in %s and %s", name, other, file)
+			}
+			files[name] = file
+		}
+	}
+
+	if err := os.MkdirAll(dir, perm); err != nil {
+		return err
+	}
+	for name, file := range files {
+		contract := contracts[file][name]
+		abiJSON, err := json.Marshal(abiOrEmpty(contract.ABI))
+		if err != nil {
+			return err
+		}
+
+		outputs := map[string][]byte{
+			".bin": []byte(contract.EVM.Bytecode.hex()),
+			".abi": abiJSON,
+		}
+		if runtime {
+			outputs[".bin-runtime"] = []byte(contract.EVM.DeployedBytecode.hex())
+		}
+		for ext, data := range outputs {
+			if err := os.WriteFile(filepath.Join(dir, name+ext), data, 0o644); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// foundryArtifact is the format of contract artifacts in Foundry's "out/"
+// directory.
+type foundryArtifact struct {
+	ABI               []json.RawMessage `json:"abi"`
+	Bytecode          foundryBytecode   `json:"bytecode"`
+	DeployedBytecode  foundryBytecode   `json:"deployedBytecode"`
+	MethodIdentifiers map[string]string `json:"methodIdentifiers,omitempty"`
+	Metadata          json.RawMessage   `json:"metadata,omitempty"`
+}
+
+type foundryBytecode struct {
+	Object         string                          `json:"object"`
+	SourceMap      string                          `json:"sourceMap,omitempty"`
+	LinkReferences map[string]map[string][]LinkRef `json:"linkReferences"`
+}
+
+func newFoundryArtifact(contract Contract) (*foundryArtifact, error) {
+	artifact := &foundryArtifact{
+		ABI:               abiOrEmpty(contract.ABI),
+		Bytecode:          newFoundryBytecode(contract.EVM.Bytecode),
+		DeployedBytecode:  newFoundryBytecode(contract.EVM.DeployedBytecode),
+		MethodIdentifiers: contract.EVM.MethodIdentifiers,
+	}
+	if contract.Metadata != "" {
+		// Foundry stores the metadata as JSON object instead of a string
+		if !json.Valid([]byte(contract.Metadata)) {
+			return nil, fmt.Errorf("invalid metadata")
+		}
+		artifact.Metadata = json.RawMessage(contract.Metadata)
+	}
+	return artifact, nil
+}
+
+func newFoundryBytecode(b Bytecode) foundryBytecode {
+	return foundryBytecode{
+		Object:         "0x" + b.hex(),
+		SourceMap:      b.SourceMap,
+		LinkReferences: linkRefsOrEmpty(b.LinkReferences),
+	}
+}
+
+// hardhatArtifact is the format of contract artifacts in Hardhat's
+// "artifacts/" directory.
+type hardhatArtifact struct {
+	Format                 string                          `json:"_format"`
+	ContractName           string                          `json:"contractName"`
+	SourceName             string                          `json:"sourceName"`
+	ABI                    []json.RawMessage               `json:"abi"`
+	Bytecode               string                          `json:"bytecode"`
+	DeployedBytecode       string                          `json:"deployedBytecode"`
+	LinkReferences         map[string]map[string][]LinkRef `json:"linkReferences"`
+	DeployedLinkReferences map[string]map[string][]LinkRef `json:"deployedLinkReferences"`
+}
+
+func newHardhatArtifact(file, name string, contract Contract) *hardhatArtifact {
+	return &hardhatArtifact{
+		Format:                 "hh-sol-artifact-1",
+		ContractName:           name,
+		SourceName:             file,
+		ABI:                    abiOrEmpty(contract.ABI),
+		Bytecode:               "0x" + contract.EVM.Bytecode.hex(),
+		DeployedBytecode:       "0x" + contract.EVM.DeployedBytecode.hex(),
+		LinkReferences:         linkRefsOrEmpty(contract.EVM.Bytecode.LinkReferences),
+		DeployedLinkReferences: linkRefsOrEmpty(contract.EVM.DeployedBytecode.LinkReferences),
+	}
+}
+
+// abiOrEmpty returns the given ABI or an empty ABI, such that it is encoded as
+// "[]" instead of "null".
+func abiOrEmpty(abi []json.RawMessage) []json.RawMessage {
+	if abi == nil {
+		return []json.RawMessage{}
+	}
+	return abi
+}
+
+// linkRefsOrEmpty returns the given link references or empty link references,
+// such that they are encoded as "{}" instead of "null".
+func linkRefsOrEmpty(refs map[string]map[string][]LinkRef) map[string]map[string][]LinkRef {
+	if refs == nil {
+		return map[string]map[string][]LinkRef{}
+	}
+	return refs
+}
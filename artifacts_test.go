@@ -0,0 +1,181 @@
+package solc
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestWriteArtifacts(t *testing.T) {
+	contracts := map[string]map[string]Contract{
+		"sub/Test.sol": {
+			"Test": {
+				ABI:      []json.RawMessage{json.RawMessage(`{"type":"constructor","inputs":[]}`)},
+				Metadata: `{"compiler":{"version":"0.8.30"}}`,
+				EVM: EVM{
+					Bytecode:          Bytecode{Object: hexBytes{0x60, 0x80}, SourceMap: "1:2:0:-:0"},
+					DeployedBytecode:  Bytecode{Object: hexBytes{0x60, 0x01}},
+					MethodIdentifiers: map[string]string{"set(uint256)": "60fe47b1"},
+				},
+			},
+		},
+	}
+
+	tests := []struct {
+		Format ArtifactFormat
+		Path   string
+		Want   string
+	}{
+		{
+			Format: ArtifactFormatFoundry,
+			Path:   "Test.sol/Test.json",
+			Want: `{
+				"abi": [{"type":"constructor","inputs":[]}],
+				"bytecode": {"object": "0x6080", "sourceMap": "1:2:0:-:0", "linkReferences": {}},
+				"deployedBytecode": {"object": "0x6001", "linkReferences": {}},
+				"methodIdentifiers": {"set(uint256)": "60fe47b1"},
+				"metadata": {"compiler":{"version":"0.8.30"}}
+			}`,
+		},
+		{
+			Format: ArtifactFormatHardhat,
+			Path:   "sub/Test.sol/Test.json",
+			Want: `{
+				"_format": "hh-sol-artifact-1",
+				"contractName": "Test",
+				"sourceName": "sub/Test.sol",
+				"abi": [{"type":"constructor","inputs":[]}],
+				"bytecode": "0x6080",
+				"deployedBytecode": "0x6001",
+				"linkReferences": {},
+				"deployedLinkReferences": {}
+			}`,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.Format), func(t *testing.T) {
+			dir := t.TempDir()
+			if err := WriteArtifacts(dir, test.Format, contracts); err != nil {
+				t.Fatalf("Failed to write artifacts: %v", err)
+			}
+
+			data, err := os.ReadFile(filepath.Join(dir, test.Path))
+			if err != nil {
+				t.Fatalf("Failed to read artifact: %v", err)
+			}
+			var got, want any
+			if err := json.Unmarshal(data, &got); err != nil {
+				t.Fatalf("Failed to decode artifact: %v", err)
+			}
+			if err := json.Unmarshal([]byte(test.Want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if diff := cmp.Diff(want, got); diff != "" {
+				t.Fatalf("(-want +got)\n%s", diff)
+			}
+		})
+	}
+
+	t.Run("unknown_format", func(t *testing.T) {
+		if err := WriteArtifacts(t.TempDir(), "truffle", contracts); err == nil {
+			t.Fatal("want error for unknown format")
+		}
+	})
+
+	t.Run("console", func(t *testing.T) {
+		dir := t.TempDir()
+		withConsole := map[string]map[string]Contract{"console.sol": {"console": {}}}
+		if err := WriteArtifacts(dir, ArtifactFormatFoundry, withConsole); err != nil {
+			t.Fatalf("Failed to write artifacts: %v", err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Fatalf("want no artifacts, got %v", entries)
+		}
+	})
+
+	t.Run("collision", func(t *testing.T) {
+		dir := t.TempDir()
+		colliding := map[string]map[string]Contract{
+			"a/Test.sol": {"Test": {}},
+			"b/Test.sol": {"Test": {}},
+		}
+		err := WriteArtifacts(dir, ArtifactFormatFoundry, colliding)
+		if err == nil || !strings.Contains(err.Error(), "a/Test.sol:Test and b/Test.sol:Test") {
+			t.Fatalf("want collision error, got %v", err)
+		}
+		if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+			t.Fatalf("want no artifacts written, got %v", entries)
+		}
+
+		// the Hardhat format keeps the source directories
+		if err := WriteArtifacts(dir, ArtifactFormatHardhat, colliding); err != nil {
+			t.Fatalf("Failed to write artifacts: %v", err)
+		}
+	})
+}
+
+func TestWriteBinABI(t *testing.T) {
+	contracts := map[string]map[string]Contract{
+		"Test.sol": {
+			"Test": {
+				ABI: []json.RawMessage{json.RawMessage(`{"type":"constructor","inputs":[]}`)},
+				EVM: EVM{Bytecode: Bytecode{Object: hexBytes{0x60, 0x80}}, DeployedBytecode: Bytecode{Object: hexBytes{0x60, 0x01}}},
+			},
+		},
+		"lib/Lib.sol": {"Lib": {}},
+	}
+
+	for _, runtime := range []bool{false, true} {
+		dir := filepath.Join(t.TempDir(), "out")
+		if err := WriteBinABI(dir, contracts, runtime); err != nil {
+			t.Fatalf("Failed to write files: %v", err)
+		}
+
+		want := map[string]string{
+			"Test.bin": "6080",
+			"Test.abi": `[{"type":"constructor","inputs":[]}]`,
+			"Lib.bin":  "",
+			"Lib.abi":  "[]",
+		}
+		if runtime {
+			want["Test.bin-runtime"] = "6001"
+			want["Lib.bin-runtime"] = ""
+		}
+		got := make(map[string]string)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, entry := range entries {
+			data, err := os.ReadFile(filepath.Join(dir, entry.Name()))
+			if err != nil {
+				t.Fatal(err)
+			}
+			got[entry.Name()] = string(data)
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("(-want +got)\n%s", diff)
+		}
+	}
+
+	// the console contracts are skipped
+	dir := t.TempDir()
+	withConsole := map[string]map[string]Contract{"console.sol": {"console": {}}}
+	if err := WriteBinABI(dir, withConsole, true); err != nil {
+		t.Fatalf("Failed to write files: %v", err)
+	}
+	if entries, _ := os.ReadDir(dir); len(entries) != 0 {
+		t.Fatalf("want no files, got %v", entries)
+	}
+
+	// contracts with the same name collide
+	contracts["Other.sol"] = map[string]Contract{"Test": {}}
+	if err := WriteBinABI(t.TempDir(), contracts, false); err == nil {
+		t.Fatal("want error for duplicate contract name")
+	}
+}
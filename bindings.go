@@ -0,0 +1,73 @@
+package solc
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+
+	"github.com/ethereum/go-ethereum/accounts/abi/abigen"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+// GenerateBindings writes type-safe Go bindings of the given contracts, keyed
+// by source file and contract name as returned by [Compiler.Compile], to w. The
+// bindings are generated by go-ethereum's abigen and belong to the given
+// package.
+//
+// The Go type of a contract is named after the contract, unless typeNames maps
+// the contract name to a different type name. Deploy methods are only
+// generated for contracts with selected bytecode. The contracts of the
+// console.sol source added by the compiler are skipped.
+func GenerateBindings(pkg string, w io.Writer, contracts map[string]map[string]Contract, typeNames map[string]string) error {
+	var (
+		types     []string
+		abis      []string
+		bytecodes []string
+		fsigs     []map[string]string
+		libs      = make(map[string]string)
+	)
+	for _, file := range slices.Sorted(maps.Keys(contracts)) {
+		if file == consoleFile {
+			continue
+		}
+		for _, name := range slices.Sorted(maps.Keys(contracts[file])) {
+			contract := contracts[file][name]
+
+			typeName := name
+			if alias, ok := typeNames[name]; ok {
+				typeName = alias
+			}
+			if slices.Contains(types, typeName) {
+				return fmt.Errorf("solc: duplicate binding type %q", typeName)
+			}
+
+			abiJSON, err := json.Marshal(abiOrEmpty(contract.ABI))
+			if err != nil {
+				return err
+			}
+			var bytecode string
+			if len(contract.EVM.Bytecode.Object) > 0 {
+				bytecode = "0x" + contract.EVM.Bytecode.hex()
+			}
+
+			types = append(types, typeName)
+			abis = append(abis, string(abiJSON))
+			bytecodes = append(bytecodes, bytecode)
+			fsigs = append(fsigs, contract.EVM.MethodIdentifiers)
+
+			// the library placeholder is the 34 character prefix of the hex
+			// encoded hash of the fully qualified library name
+			libPattern := crypto.Keccak256Hash([]byte(file + ":" + name)).Hex()[2:36]
+			libs[libPattern] = typeName
+		}
+	}
+
+	code, err := abigen.Bind(types, abis, bytecodes, fsigs, pkg, libs, nil)
+	if err != nil {
+		return fmt.Errorf("solc: failed to generate bindings: %w", err)
+	}
+	_, err = io.WriteString(w, code)
+	return err
+}
@@ -0,0 +1,48 @@
+package solc
+
+import (
+	"encoding/json"
+	"go/parser"
+	"go/token"
+	"strings"
+	"testing"
+)
+
+func TestGenerateBindings(t *testing.T) {
+	contracts := map[string]map[string]Contract{
+		"Test.sol": {
+			"Test": {
+				ABI: []json.RawMessage{
+					json.RawMessage(`{"type":"function","name":"set","inputs":[{"name":"x","type":"uint256"}],"outputs":[],"stateMutability":"nonpayable"}`),
+				},
+				EVM: EVM{
+					Bytecode:          Bytecode{Object: hexBytes{0x60, 0x80}},
+					MethodIdentifiers: map[string]string{"set(uint256)": "60fe47b1"},
+				},
+			},
+		},
+		"console.sol": {"console": {}},
+	}
+
+	var sb strings.Builder
+	if err := GenerateBindings("bindings", &sb, contracts, map[string]string{"Test": "Store"}); err != nil {
+		t.Fatalf("Failed to generate bindings: %v", err)
+	}
+	code := sb.String()
+
+	file, err := parser.ParseFile(token.NewFileSet(), "bindings.go", code, 0)
+	if err != nil {
+		t.Fatalf("Failed to parse bindings: %v", err)
+	}
+	if file.Name.Name != "bindings" {
+		t.Fatalf("want package bindings, got %s", file.Name.Name)
+	}
+	for _, want := range []string{"type Store struct", "func DeployStore(", "func (_Store *StoreTransactor) Set("} {
+		if !strings.Contains(code, want) {
+			t.Errorf("want bindings to contain %q", want)
+		}
+	}
+	if strings.Contains(code, "type Console struct") {
+		t.Error("want no bindings of the console contracts")
+	}
+}
@@ -0,0 +1,450 @@
+package solc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"hash"
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/sync/singleflight"
+)
+
+// Cache is a persistent cache of compilation outputs, e.g. shared by multiple
+// machines. Keys are derived from the solc version and the complete input of a
+// compilation, values are raw standard JSON outputs of solc.
+//
+// Outputs are additionally cached in memory, such that the Cache is only
+// accessed once per key and process.
+type Cache interface {
+	// Get returns the cached value of the given key and true, or false if the
+	// key is not cached.
+	Get(key string) ([]byte, bool)
+
+	// Set caches the given value under the given key.
+	Set(key string, value []byte)
+}
+
+// cacheVersion is the version of the on-disk cache format. It is written as
+// first byte of each cache file and must be incremented whenever the format of
+// cache files or cache keys changes.
+const cacheVersion byte = 2
+
+var (
+	// global compiler cache
+	group    = new(singleflight.Group)
+	cacheMux sync.RWMutex
+	cache    = make(map[string]cacheItem)
+
+	// global cache statistics
+	cacheHits   atomic.Uint64
+	cacheMisses atomic.Uint64
+)
+
+type cacheItem struct {
+	out []byte
+	err error
+}
+
+// CacheStats are statistics of the compilation output cache.
+type CacheStats struct {
+	Hits    uint64 // Number of outputs loaded from the in-memory cache or a [Cache], or shared with a concurrent run
+	Misses  uint64 // Number of outputs that were not cached
+	Entries int    // Number of outputs in the in-memory cache
+}
+
+// CacheStats returns statistics of the compilation output cache. The in-memory
+// cache and its statistics are shared by all [Compiler]s.
+func (c *Compiler) CacheStats() CacheStats {
+	cacheMux.RLock()
+	entries := len(cache)
+	cacheMux.RUnlock()
+
+	return CacheStats{
+		Hits:    cacheHits.Load(),
+		Misses:  cacheMisses.Load(),
+		Entries: entries,
+	}
+}
+
+// ClearCache removes all cached compilation outputs from memory and the
+// compiler's [Cache], if the [Cache] has a method "Clear() error" like
+// [DiskCache]. The in-memory cache is shared by all [Compiler]s.
+func (c *Compiler) ClearCache() error {
+	cacheMux.Lock()
+	clear(cache)
+	cacheMux.Unlock()
+
+	if clearer, ok := c.persistentCache().(interface{ Clear() error }); ok {
+		return clearer.Clear()
+	}
+	return nil
+}
+
+// persistentCache returns the [Cache] of the compiler. If no [Cache] was set
+// using [WithCache], a [DiskCache] in the bin directory is used. If the
+// compiler has no bin directory either, nil is returned.
+func (c *Compiler) persistentCache() Cache {
+	if c.cache != nil {
+		return c.cache
+	}
+	if c.binPath == "" {
+		return nil
+	}
+	return NewDiskCache(filepath.Join(c.binPath, "cache"))
+}
+
+// runStats are statistics of a call of [Compiler.runRawWithCache].
+type runStats struct {
+	fromCache    bool          // output was served from the cache
+	solcDuration time.Duration // duration of the solc run, zero if solc was not run by the call
+}
+
+// runRawWithCache runs solc with the given arguments and standard JSON input
+// and returns its raw output. Outputs are cached in memory and in the
+// compiler's [Cache]. If noCache is set, the cache is not read, but updated
+// with the new output.
+//
+// Concurrent calls with the same input share a single solc run, which only
+// the stats of the call running solc reflect.
+func (c *Compiler) runRawWithCache(ctx context.Context, dir string, args []string, inputJSON []byte, noCache bool) ([]byte, runStats, error) {
+	var stats runStats
+	cacheKey, err := c.rawCacheKey(dir, inputJSON, args)
+	if err != nil {
+		return nil, stats, err
+	}
+
+	if noCache {
+		c.log().Debug("cache skipped", "key", cacheKey)
+		start := time.Now()
+		out, err := c.run(ctx, dir, args, inputJSON)
+		stats.solcDuration = time.Since(start)
+		if ctx.Err() == nil {
+			c.updateCache(cacheKey, out, err)
+		}
+		return out, stats, err
+	}
+
+	// run with cache
+	var leader bool // whether this call ran the function of the group
+	out, err, shared := group.Do(c.memCacheKey(cacheKey), func() (any, error) {
+		leader = true
+
+		// check cache
+		cacheMux.RLock()
+		val, ok := cache[c.memCacheKey(cacheKey)]
+		cacheMux.RUnlock()
+		if ok {
+			cacheHits.Add(1)
+			stats.fromCache = true
+			c.log().Debug("cache hit", "key", cacheKey, "cache", "memory")
+			return val.out, val.err
+		}
+
+		// check persistent cache
+		if out, ok := c.getCache(cacheKey); ok {
+			cacheHits.Add(1)
+			stats.fromCache = true
+			c.log().Debug("cache hit", "key", cacheKey, "cache", "persistent")
+			cacheMux.Lock()
+			cache[c.memCacheKey(cacheKey)] = cacheItem{out, nil}
+			cacheMux.Unlock()
+			return out, nil
+		}
+		cacheMisses.Add(1)
+		c.log().Debug("cache miss", "key", cacheKey)
+
+		// run solc
+		start := time.Now()
+		out, err := c.run(ctx, dir, args, inputJSON)
+		stats.solcDuration = time.Since(start)
+		if ctx.Err() != nil {
+			// don't cache the result of canceled runs
+			return out, err
+		}
+
+		c.updateCache(cacheKey, out, err)
+		return out, err
+	})
+	if shared && !leader {
+		// the output of a concurrent identical call was reused
+		cacheHits.Add(1)
+		stats.fromCache = true
+	}
+	if err != nil {
+		return nil, stats, err
+	}
+	return out.([]byte), stats, nil
+}
+
+// memCacheKey returns the key under which the output of the given cache key is
+// cached in memory. Outputs in memory are additionally keyed by the solc binary,
+// such that compilers with different bin directories don't share outputs.
+func (c *Compiler) memCacheKey(key string) string {
+	return c.solcAbsPath + "\x00" + key
+}
+
+// getCache returns the output of the given key from the compiler's [Cache].
+func (c *Compiler) getCache(key string) ([]byte, bool) {
+	persistent := c.persistentCache()
+	if persistent == nil {
+		return nil, false
+	}
+	return persistent.Get(key)
+}
+
+// updateCache stores the given result of a solc run in the in-memory cache and,
+// if the run succeeded, in the compiler's [Cache].
+func (c *Compiler) updateCache(key string, out []byte, err error) {
+	cacheMux.Lock()
+	cache[c.memCacheKey(key)] = cacheItem{out, err}
+	cacheMux.Unlock()
+	if persistent := c.persistentCache(); persistent != nil && err == nil {
+		persistent.Set(key, out)
+	}
+}
+
+// cacheKey returns the key under which the output of the given input is
+// cached. The key covers the solc version, all settings of the input, the
+// content of all sources and the solc command line arguments.
+func (c *Compiler) cacheKey(in *input, args []string) (string, error) {
+	inputJSON, err := in.marshal()
+	if err != nil {
+		return "", err
+	}
+	return c.rawCacheKey("", inputJSON, args)
+}
+
+// rawCacheKey returns the key under which the output of the given standard
+// JSON input is cached. The key does not depend on where the sources are
+// located: sources that are given by URL are read relative to the given
+// directory and keyed by their content, and the directory is not part of the
+// key.
+//
+// The key also covers the files solc reads itself, i.e. the imported files
+// that are not part of the input, such as sibling files of a single compiled
+// file or files imported via remappings, by source unit name and content.
+func (c *Compiler) rawCacheKey(dir string, inputJSON []byte, args []string) (string, error) {
+	// replace source URLs by their content
+	normInputJSON, err := inlineSources(dir, inputJSON)
+	if err != nil {
+		return "", err
+	}
+
+	// read the files solc reads itself
+	var in struct {
+		Sources  map[string]src `json:"sources"`
+		Settings struct {
+			Remappings []string `json:"remappings"`
+		} `json:"settings"`
+	}
+	if err := json.Unmarshal(normInputJSON, &in); err != nil {
+		return "", err
+	}
+	imported, _ := importClosure(dir, in.Sources, in.Settings.Remappings, argsLookupDirs(dir, args))
+
+	h := sha256.New()
+	h.Write(normInputJSON)
+	for _, arg := range relativeArgs(dir, args) {
+		h.Write([]byte{0})
+		h.Write([]byte(arg))
+	}
+	writeImported(h, imported)
+	var hash [32]byte
+	h.Sum(hash[:0])
+
+	return fmt.Sprintf("%s_%x", c.version, hash), nil
+}
+
+// writeImported writes the given imported files, keyed by source unit name, to
+// the given hash.
+func writeImported(h hash.Hash, imported map[string]string) {
+	for _, name := range slices.Sorted(maps.Keys(imported)) {
+		h.Write([]byte{1})
+		h.Write([]byte(name))
+		h.Write([]byte{0})
+		h.Write([]byte(imported[name]))
+	}
+}
+
+// inlineSources returns the given standard JSON input with the sources that
+// are given by URL replaced by their content, see [readSource].
+func inlineSources(dir string, inputJSON []byte) ([]byte, error) {
+	var in map[string]json.RawMessage
+	if err := json.Unmarshal(inputJSON, &in); err != nil {
+		return nil, err
+	}
+
+	if sourcesJSON, ok := in["sources"]; ok {
+		var sources map[string]src
+		if err := json.Unmarshal(sourcesJSON, &sources); err != nil {
+			return nil, err
+		}
+		for name, source := range sources {
+			sources[name] = readSource(dir, source)
+		}
+
+		var err error
+		if in["sources"], err = json.Marshal(sources); err != nil {
+			return nil, err
+		}
+	}
+	return json.Marshal(in)
+}
+
+// readSource returns the given source with its URLs replaced by the content of
+// the first readable URL. Relative URLs are resolved against the given
+// directory. If no URL is readable, the source is returned as is.
+func readSource(dir string, source src) src {
+	for _, url := range source.URLS {
+		if !filepath.IsAbs(url) {
+			url = filepath.Join(dir, url)
+		}
+		content, err := os.ReadFile(url)
+		if err != nil {
+			continue
+		}
+		return src{Keccak256: source.Keccak256, Content: string(content)}
+	}
+	return source
+}
+
+// SourceHash returns the hex encoded SHA256 hash of the given sources, which
+// map source file names to their content, as they are covered by the keys of
+// cached compilation outputs: sources with the same hash are the same sources
+// for the cache. Besides the sources, cache keys cover the solc version, the
+// compilation settings and the solc command line arguments.
+func SourceHash(sources map[string]string) string {
+	srcMap := make(map[string]src, len(sources))
+	for name, content := range sources {
+		srcMap[name] = src{Content: content}
+	}
+	return sourceHash(srcMap, nil)
+}
+
+// SourceHashDir is like [SourceHash] but hashes the Solidity sources in the
+// given directory, named relative to the directory as by [Compiler.Compile],
+// and the files outside the directory they import, such as packages in
+// node_modules. Imports are resolved as by [Compiler.Compile] without options,
+// so files only reachable via [WithRemappings], [WithBasePath] or
+// [WithIncludePaths] are not covered.
+func SourceHashDir(dir string) (string, error) {
+	absDir, srcMap, err := loadDir(dir, langSolidity.ext())
+	if err != nil {
+		return "", err
+	}
+	for name, source := range srcMap {
+		if srcMap[name] = readSource(absDir, source); len(srcMap[name].URLS) > 0 {
+			return "", fmt.Errorf("solc: failed to read source %q", name)
+		}
+	}
+
+	lookupDirs := lookupDirs(absDir, "", nil)
+	remappings := nodeModulesRemappings(absDir, srcMap, nil, lookupDirs)
+	imported, _ := importClosure(absDir, srcMap, remappings, lookupDirs)
+	return sourceHash(srcMap, imported), nil
+}
+
+// sourceHash returns the hex encoded SHA256 hash of the given sources, which
+// must be given by content, and of the given imported files, keyed by source
+// unit name.
+func sourceHash(srcMap map[string]src, imported map[string]string) string {
+	sourcesJSON, _ := json.Marshal(srcMap) // can't fail
+	h := sha256.New()
+	h.Write(sourcesJSON)
+	writeImported(h, imported)
+	return fmt.Sprintf("%x", h.Sum(nil))
+}
+
+// relativeArgs returns the given solc arguments without the given directory in
+// the list of allowed paths and with the other allowed paths made relative to
+// it, so cache keys don't depend on the location of the project.
+func relativeArgs(dir string, args []string) []string {
+	if dir == "" {
+		return args
+	}
+
+	args = slices.Clone(args)
+	for i := 0; i+1 < len(args); i++ {
+		if args[i] != "--allow-paths" {
+			continue
+		}
+		var paths []string
+		for _, path := range strings.Split(args[i+1], ",") {
+			if path == dir {
+				continue
+			}
+			if rel, err := filepath.Rel(dir, path); err == nil && filepath.IsAbs(path) {
+				path = filepath.ToSlash(rel)
+			}
+			paths = append(paths, path)
+		}
+		args[i+1] = strings.Join(paths, ",")
+	}
+	return args
+}
+
+// DiskCache is a [Cache] that stores each value in a file in a directory. It is
+// the default [Cache] of a [Compiler], which uses the directory
+// "<binPath>/cache/".
+type DiskCache struct {
+	dir string
+}
+
+// NewDiskCache returns a [DiskCache] that stores values in the given directory.
+// The directory is created on the first write.
+func NewDiskCache(dir string) *DiskCache {
+	return &DiskCache{dir: dir}
+}
+
+func (d *DiskCache) path(key string) string {
+	return filepath.Join(d.dir, key+".json")
+}
+
+// Get returns the cached value of the given key. Cache files of a different
+// cache version are ignored.
+func (d *DiskCache) Get(key string) ([]byte, bool) {
+	data, err := os.ReadFile(d.path(key))
+	if err != nil || len(data) == 0 || data[0] != cacheVersion {
+		return nil, false
+	}
+	return data[1:], true
+}
+
+// Set writes the given value to the cache. The cache file is written to a
+// temporary file first and renamed once it is complete. Errors are ignored, as
+// the cache is best effort.
+func (d *DiskCache) Set(key string, value []byte) {
+	if err := os.MkdirAll(d.dir, perm); err != nil {
+		return
+	}
+
+	path := d.path(key)
+	f, err := os.CreateTemp(d.dir, filepath.Base(path)+".*.tmp")
+	if err != nil {
+		return
+	}
+	defer os.Remove(f.Name())
+	defer f.Close()
+
+	if _, err := f.Write(append([]byte{cacheVersion}, value...)); err != nil {
+		return
+	}
+	if err := f.Close(); err != nil {
+		return
+	}
+	os.Rename(f.Name(), path)
+}
+
+// Clear removes all cached values.
+func (d *DiskCache) Clear() error {
+	return os.RemoveAll(d.dir)
+}
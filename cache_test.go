@@ -0,0 +1,434 @@
+package solc
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestDiskCache(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts":{"Test.sol":{"Test":{"abi":[]}}}}`)
+	c.binPath = t.TempDir()
+
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	want, err := c.Compile(srcDir, "Test", nil)
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+
+	// the cache file starts with the cache version
+	files, err := filepath.Glob(filepath.Join(c.binPath, "cache", "*.json"))
+	if err != nil || len(files) != 1 {
+		t.Fatalf("want 1 cache file, got %d (%v)", len(files), err)
+	}
+	data, err := os.ReadFile(files[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data[0] != cacheVersion {
+		t.Fatalf("want cache version %d, got %d", cacheVersion, data[0])
+	}
+
+	// clear the in-memory cache and break solc, such that the output must be
+	// loaded from disk
+	cacheMux.Lock()
+	clear(cache)
+	cacheMux.Unlock()
+	if err := os.WriteFile(c.solcAbsPath, []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.Compile(srcDir, "Test", nil)
+	if err != nil {
+		t.Fatalf("Failed to compile from disk cache: %v", err)
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(Contract{})); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+
+	// cache files of other cache versions are ignored
+	data[0] = cacheVersion + 1
+	if err := os.WriteFile(files[0], data, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	cacheMux.Lock()
+	clear(cache)
+	cacheMux.Unlock()
+	if _, err := c.Compile(srcDir, "Test", nil); err == nil {
+		t.Fatal("want error, got nil")
+	}
+}
+
+func TestCacheKeySourceContent(t *testing.T) {
+	c := &Compiler{version: VersionLatest}
+
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	s, err := c.buildSettings(nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cacheKey := func() string {
+		t.Helper()
+		_, srcMap, err := loadDir(srcDir, ".sol")
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := c.cacheKey(buildInput(srcMap, s), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return key
+	}
+
+	key1 := cacheKey()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.1;")
+	key2 := cacheKey()
+	if key1 == key2 {
+		t.Fatal("want different cache keys for different source content")
+	}
+}
+
+func TestClearCache(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts":{"Test.sol":{"Test":{"abi":[]}}}}`)
+	c.binPath = t.TempDir()
+
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	// ran reports whether solc ran since the last call, by checking whether
+	// the fake solc wrote its input
+	inputPath := filepath.Join(filepath.Dir(c.solcAbsPath), "input.json")
+	ran := func() bool {
+		t.Helper()
+		err := os.Remove(inputPath)
+		if err != nil && !os.IsNotExist(err) {
+			t.Fatal(err)
+		}
+		return err == nil
+	}
+
+	compile := func(compile func(dir, contract string, outputSelection map[string]map[string][]string, opts ...Option) (map[string]map[string]Contract, error)) {
+		t.Helper()
+		if _, err := compile(srcDir, "Test", nil); err != nil {
+			t.Fatalf("Failed to compile: %v", err)
+		}
+	}
+
+	compile(c.Compile)
+	if !ran() {
+		t.Fatal("want solc to run on first compile")
+	}
+	compile(c.Compile)
+	if ran() {
+		t.Fatal("want cached output on second compile")
+	}
+	compile(c.CompileNoCache)
+	if !ran() {
+		t.Fatal("want solc to run on CompileNoCache")
+	}
+
+	if err := c.ClearCache(); err != nil {
+		t.Fatalf("Failed to clear cache: %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(c.binPath, "cache")); !os.IsNotExist(err) {
+		t.Fatalf("want disk cache removed, got %v", err)
+	}
+	compile(c.Compile)
+	if !ran() {
+		t.Fatal("want solc to run after ClearCache")
+	}
+}
+
+func TestCacheStats(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts":{"Test.sol":{"Test":{"abi":[]}}}}`)
+	c.binPath = t.TempDir()
+
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	if err := c.ClearCache(); err != nil {
+		t.Fatalf("Failed to clear cache: %v", err)
+	}
+	before := c.CacheStats()
+	if before.Entries != 0 {
+		t.Fatalf("want 0 entries, got %d", before.Entries)
+	}
+
+	for range 3 {
+		if _, err := c.Compile(srcDir, "Test", nil); err != nil {
+			t.Fatalf("Failed to compile: %v", err)
+		}
+	}
+
+	got := c.CacheStats()
+	want := CacheStats{
+		Hits:    before.Hits + 2,
+		Misses:  before.Misses + 1,
+		Entries: 1,
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
+
+func TestCacheKeyPathIndependent(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts":{"Test.sol":{"Test":{"abi":[]}}}}`)
+
+	var keys []string
+	for range 2 {
+		srcDir := t.TempDir()
+		createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+		s, err := c.buildSettings(nil, []Option{WithRemappings([]string{"lib/=lib/"})})
+		if err != nil {
+			t.Fatal(err)
+		}
+		absDir, srcMap, err := loadDir(srcDir, ".sol")
+		if err != nil {
+			t.Fatal(err)
+		}
+		inputJSON, err := json.Marshal(buildInput(srcMap, s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := c.rawCacheKey(absDir, inputJSON, solcArgs(absDir, s))
+		if err != nil {
+			t.Fatal(err)
+		}
+		keys = append(keys, key)
+	}
+
+	if keys[0] != keys[1] {
+		t.Fatalf("want equal cache keys for equal sources in different directories, got %s and %s", keys[0], keys[1])
+	}
+}
+
+// mapCache is a [Cache] backed by a map.
+type mapCache map[string][]byte
+
+func (m mapCache) Get(key string) ([]byte, bool) {
+	value, ok := m[key]
+	return value, ok
+}
+
+func (m mapCache) Set(key string, value []byte) { m[key] = value }
+
+func TestWithCache(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts":{"Test.sol":{"Test":{"abi":[]}}}}`)
+	c.binPath = t.TempDir()
+	mc := make(mapCache)
+	WithCache(mc)(c)
+
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	want, err := c.Compile(srcDir, "Test", nil)
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	if len(mc) != 1 {
+		t.Fatalf("want 1 cache entry, got %d", len(mc))
+	}
+
+	// the custom cache replaces the disk cache
+	if _, err := os.Stat(filepath.Join(c.binPath, "cache")); !os.IsNotExist(err) {
+		t.Fatalf("want no disk cache, got %v", err)
+	}
+
+	// clear the in-memory cache and break solc, such that the output must be
+	// loaded from the custom cache
+	cacheMux.Lock()
+	clear(cache)
+	cacheMux.Unlock()
+	if err := os.WriteFile(c.solcAbsPath, []byte("#!/bin/sh\nexit 1\n"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := c.Compile(srcDir, "Test", nil)
+	if err != nil {
+		t.Fatalf("Failed to compile from custom cache: %v", err)
+	}
+	if diff := cmp.Diff(want, got, cmpopts.IgnoreUnexported(Contract{})); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
+
+func TestCacheSeparateBinPaths(t *testing.T) {
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	for _, name := range []string{"A", "B"} {
+		binPath := t.TempDir()
+		script := "#!/bin/sh\ncat > /dev/null\n" +
+			`echo '{"contracts": {"Test.sol": {"` + name + `": {}}}}'` + "\n"
+		if err := os.WriteFile(filepath.Join(binPath, "solc_v"+VersionLatest.String()), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+
+		c, err := New(VersionLatest, binPath, WithOffline())
+		if err != nil {
+			t.Fatalf("Failed to create compiler: %v", err)
+		}
+		contracts, err := c.CompileAll(srcDir, nil)
+		if err != nil {
+			t.Fatalf("Compile failed: %v", err)
+		}
+		if _, ok := contracts["Test.sol"][name]; !ok {
+			t.Fatalf("want output of the compiler's own binary, got %v", contracts)
+		}
+	}
+}
+
+func TestSourceHash(t *testing.T) {
+	sources := map[string]string{"A.sol": "contract A {}", "lib/B.sol": "contract B {}"}
+	hash := SourceHash(sources)
+	if len(hash) != 64 {
+		t.Fatalf("want hex encoded SHA256 hash, got %q", hash)
+	}
+
+	// changes of content and names change the hash
+	for _, other := range []map[string]string{
+		{"A.sol": "contract A { }", "lib/B.sol": "contract B {}"},
+		{"A.sol": "contract A {}", "B.sol": "contract B {}"},
+		{"A.sol": "contract A {}"},
+	} {
+		if SourceHash(other) == hash {
+			t.Fatalf("want different hash for %v", other)
+		}
+	}
+
+	// the hash of a directory matches the hash of its sources
+	dir := t.TempDir()
+	if err := os.Mkdir(filepath.Join(dir, "lib"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	createDummyContract(t, dir, "A", sources["A.sol"])
+	createDummyContract(t, filepath.Join(dir, "lib"), "B", sources["lib/B.sol"])
+	dirHash, err := SourceHashDir(dir)
+	if err != nil {
+		t.Fatalf("Failed to hash directory: %v", err)
+	}
+	if dirHash != hash {
+		t.Fatalf("want %s, got %s", hash, dirHash)
+	}
+
+	// edits of imported files outside the directory change the hash
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "src")
+	pkgDir := filepath.Join(root, "node_modules", "@lib", "pkg")
+	for _, dir := range []string{srcDir, pkgDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	createDummyContract(t, srcDir, "Test", `import "@lib/pkg/Lib.sol";`)
+	createDummyContract(t, pkgDir, "Lib", "library Lib {}")
+	before, err := SourceHashDir(srcDir)
+	if err != nil {
+		t.Fatalf("Failed to hash directory: %v", err)
+	}
+	createDummyContract(t, pkgDir, "Lib", "library Lib { }")
+	after, err := SourceHashDir(srcDir)
+	if err != nil {
+		t.Fatalf("Failed to hash directory: %v", err)
+	}
+	if before == after {
+		t.Fatal("want different hash after editing an imported file")
+	}
+}
+
+func TestCacheKeyImportedFiles(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts":{"Test.sol":{"Test":{"abi":[]}}}}`)
+	srcDir, libDir := t.TempDir(), t.TempDir()
+	createDummyContract(t, srcDir, "Test", "import \"./Lib.sol\";\nimport \"lib/Ext.sol\";")
+	createDummyContract(t, srcDir, "Lib", "library Lib {}")
+	createDummyContract(t, libDir, "Ext", "library Ext {}")
+	opt := WithRemappings([]string{"lib/=" + libDir + "/"})
+
+	compile := func() uint64 {
+		t.Helper()
+		misses := c.CacheStats().Misses
+		if _, err := c.CompileFile(filepath.Join(srcDir, "Test.sol"), "Test", nil, opt); err != nil {
+			t.Fatalf("Failed to compile: %v", err)
+		}
+		return c.CacheStats().Misses - misses
+	}
+
+	if misses := compile(); misses != 1 {
+		t.Fatalf("want cache miss, got %d misses", misses)
+	}
+	if misses := compile(); misses != 0 {
+		t.Fatalf("want cache hit, got %d misses", misses)
+	}
+
+	// changes of files solc reads itself invalidate the cache
+	createDummyContract(t, srcDir, "Lib", "library Lib { }")
+	if misses := compile(); misses != 1 {
+		t.Fatalf("want cache miss after changing a sibling file, got %d misses", misses)
+	}
+	createDummyContract(t, libDir, "Ext", "library Ext { }")
+	if misses := compile(); misses != 1 {
+		t.Fatalf("want cache miss after changing a remapped file, got %d misses", misses)
+	}
+}
+
+func TestCacheSharedRun(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts":{"Test.sol":{"Test":{"abi":[]}}}}`)
+
+	// make the fake solc wait for a release file, such that a second compile
+	// of the same input waits for the running one
+	dir := filepath.Dir(c.solcAbsPath)
+	script := "#!/bin/sh\ncat > \"$(dirname \"$0\")/input.json\"\n" +
+		"while [ ! -f \"$(dirname \"$0\")/release\" ]; do sleep 0.01; done\n" +
+		"echo '{\"contracts\":{\"Test.sol\":{\"Test\":{\"abi\":[]}}}}'\n"
+	if err := os.WriteFile(c.solcAbsPath, []byte(script), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	stats := c.CacheStats()
+	metas := make(chan Meta, 2)
+	compile := func() {
+		_, meta, err := c.CompileWithMeta(srcDir, "Test", nil)
+		if err != nil {
+			t.Errorf("Compile failed: %v", err)
+		}
+		metas <- meta
+	}
+	go compile()
+	for !fileExists(filepath.Join(dir, "input.json")) {
+		time.Sleep(10 * time.Millisecond)
+	}
+	go compile()
+	time.Sleep(100 * time.Millisecond)
+	if err := os.WriteFile(filepath.Join(dir, "release"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	var fromCache int
+	for range 2 {
+		if meta := <-metas; meta.FromCache {
+			fromCache++
+		}
+	}
+	if fromCache != 1 {
+		t.Fatalf("want 1 output from cache, got %d", fromCache)
+	}
+	if got := c.CacheStats().Misses - stats.Misses; got != 1 {
+		t.Fatalf("want 1 cache miss, got %d", got)
+	}
+	if got := c.CacheStats().Hits - stats.Hits; got != 1 {
+		t.Fatalf("want 1 cache hit, got %d", got)
+	}
+}
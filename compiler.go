@@ -2,69 +2,473 @@ package solc
 
 import (
 	"bytes"
+	"context"
 	"crypto/sha256"
 	_ "embed"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
+	"log/slog"
+	"maps"
+	"math"
+	"net/http"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
+	"slices"
 	"strings"
-	"sync"
+	"sync/atomic"
+	"time"
 
+	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/raszia/go-solc/internal/console"
-	"golang.org/x/sync/singleflight"
 )
 
 var (
 	perm = os.FileMode(0o0775)
 
-	// global compiler cache
-	group    = new(singleflight.Group)
-	cacheMux sync.RWMutex
-	cache    = make(map[string]cacheItem)
-)
+	// discardLogger is the logger of compilers without logger.
+	discardLogger = slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.Level(math.MaxInt)}))
 
-type cacheItem struct {
-	out *output
-	err error
-}
+	// errTimeout is the cause of compilations killed by [WithTimeout].
+	errTimeout = fmt.Errorf("compilation timed out: %w", context.DeadlineExceeded)
+)
 
 type Compiler struct {
-	binPath string  // Path to the solc binary
-	version Version // Solc version
+	binPath  string                        // Path to the solc binary
+	version  Version                       // Solc version
+	offline  bool                          // Only use installed solc binaries
+	baseURL  string                        // Base URL to download solc binaries from
+	cache    Cache                         // Persistent cache of compilation outputs
+	progress func(downloaded, total int64) // Download progress callback
+	client   *http.Client                  // HTTP client for downloads
+	retry    *retryPolicy                  // Retry policy of downloads, default if nil
+	logger   *slog.Logger                  // Logger, silent if nil
+
+	checksums   map[Version]string // Pinned SHA256 checksums of solc binaries
+	solJSRunner SolJSRunner        // Runner of soljson.js if there is no native solc binary
+
+	concurrency int // Maximum number of concurrent solc processes of CompileMany
+
+	downloadDuration time.Duration // Duration of the download of solc, zero if it was installed
+	downloadReported atomic.Bool   // Whether the download duration was reported in a Timing
 
 	solcAbsPath string // solc absolute path
 
 }
 
-func New(version Version, binPath string) (*Compiler, error) {
+// New returns a new [Compiler] for the given solc version. The solc binary is
+// downloaded to the given bin directory if it is not installed yet.
+//
+// Compilers with different bin directories are independent of each other. The
+// bin directory has the following layout and may be managed externally, e.g.
+// to install binaries in advance:
+//
+//	<binPath>/
+//	├── cache/                 cached compilation outputs, see [DiskCache]
+//	├── tmp/                   temporary files, see [Compiler.CleanTemp]
+//	├── solc_v<version>        solc binary
+//	└── soljson_v<version>.js  soljson.js build, see [WithSolJSRunner]
+//
+// Instead of an exact version, a version constraint such as "^0.8.0" may be
+// given, which is resolved using [Resolve].
+func New(version Version, binPath string, opts ...CompilerOption) (*Compiler, error) {
 	c := &Compiler{
-		version: version,
 		binPath: binPath,
 	}
+	for _, opt := range opts {
+		opt(c)
+	}
+
 	var err error
-	c.solcAbsPath, err = checkSolc(c.version, binPath)
+	if c.version, err = c.resolveVersion(version); err != nil {
+		return c, err
+	}
+	c.log().Debug("resolved solc version", "constraint", version, "version", c.version)
+
+	c.solcAbsPath, err = c.checkSolc()
 	return c, err
 }
 
+// log returns the logger of the compiler.
+func (c *Compiler) log() *slog.Logger {
+	if c.logger == nil {
+		return discardLogger
+	}
+	return c.logger
+}
+
+// Version returns the solc version of the compiler. If the compiler was
+// created with a version constraint, the resolved version is returned.
+func (c *Compiler) Version() Version { return c.version }
+
+// LongVersion returns the long version of the solc binary used by the
+// compiler, including the commit hash, e.g. "0.8.30+commit.73712a01". If the
+// long version is unknown, the version is returned.
+func (c *Compiler) LongVersion() string {
+	return longVersion(c.version)
+}
+
+func longVersion(version Version) string {
+	v, ok := solcVersions[version]
+	if !ok {
+		return string(version)
+	}
+	i := strings.LastIndex(v.Path, "-v")
+	if i < 0 {
+		return string(version)
+	}
+	return strings.TrimSuffix(v.Path[i+2:], ".exe")
+}
+
+// Invocation is the solc invocation of a compilation. If the output of the
+// compilation was served from the cache, solc was not run, but running the
+// invocation reproduces the output.
+type Invocation struct {
+	Args      []string // Command line, starting with the path of the solc binary.
+	Dir       string   // Working directory, empty for the current directory or an empty directory with [WithNoFilesystem].
+	InputHash [32]byte // SHA256 hash of the standard JSON input passed to stdin.
+}
+
+// BinaryPath returns the path of the solc binary used by the compiler.
+func (c *Compiler) BinaryPath() string { return c.solcAbsPath }
+
+// resolveVersion resolves the given version if it is a version constraint. In
+// offline mode only installed versions are considered.
+func (c *Compiler) resolveVersion(version Version) (Version, error) {
+	if _, ok := solcVersions[version]; ok {
+		return version, nil
+	}
+
+	versions := Versions
+	if c.offline {
+		installed, err := ListInstalled(c.binPath)
+		if err != nil {
+			return "", err
+		}
+		versions = installed
+	}
+
+	if v, err := resolve(string(version), versions); err == nil {
+		return v, nil
+	}
+	return version, nil
+}
+
 // Compile all contracts in the given directory and return the contract code of
-// the contract with the given name.
+// the contract with the given name. If the contract name is empty, all
+// contracts are returned. If no contract has the given name, an error is
+// returned.
+//
+// All source files in the directory and its subdirectories are compiled
+// together, even if only a single contract is returned, so contracts may
+// import each other. Imported files outside the directory are read by solc.
+//
+// The returned contracts are keyed by source file and contract name. Source
+// files in the directory are named by their slash-separated path relative to
+// the directory, even if [WithBasePath] is set. Sources that solc reports by
+// absolute path, e.g. if imported via a remapping to an absolute path, are
+// named relative to the base path, which defaults to the directory. Sources
+// outside the base path keep their absolute path.
+//
+// Imports of packages, e.g. "@openzeppelin/contracts/token/ERC20/ERC20.sol",
+// are resolved against the nearest node_modules directory of the directory or
+// its parents, unless they match a remapping (see [WithRemappings]) or a file
+// relative to the directory.
+//
+// Before solc is run, the version pragmas of all Solidity sources are checked
+// against the solc version of the compiler. If the version does not satisfy a
+// pragma, an error naming the source files and their pragmas is returned.
 func (c *Compiler) Compile(dir, contract string, outputSelection map[string]map[string][]string, opts ...Option) (map[string]map[string]Contract, error) {
-	out, err := c.compile(dir, outputSelection, opts)
+	return c.CompileContext(context.Background(), dir, contract, outputSelection, opts...)
+}
+
+// CompileAll is like [Compiler.Compile] but returns all contracts in the given
+// directory.
+func (c *Compiler) CompileAll(dir string, outputSelection map[string]map[string][]string, opts ...Option) (map[string]map[string]Contract, error) {
+	return c.Compile(dir, "", outputSelection, opts...)
+}
+
+// CompileToABI is like [Compiler.CompileAll] but only returns the parsed ABIs of
+// all contracts, keyed by contract name. If the output selection is nil, only
+// the ABI is selected. The contracts of the console.sol source added by the
+// compiler are skipped. An error is returned if multiple source files contain a
+// contract with the same name.
+func (c *Compiler) CompileToABI(dir string, outputSelection map[string]map[string][]string, opts ...Option) (map[string]abi.ABI, error) {
+	if outputSelection == nil {
+		outputSelection = OutputABI()
+	}
+	contracts, err := c.CompileAll(dir, outputSelection, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	abis := make(map[string]abi.ABI)
+	files := make(map[string]string) // source file by contract name
+	for _, file := range slices.Sorted(maps.Keys(contracts)) {
+		if file == consoleFile {
+			continue
+		}
+		for name, contract := range contracts[file] {
+			if other, ok := files[name]; ok {
+				return nil, fmt.Errorf("solc: contract %s is defined in %s and %s", name, other, file)
+			}
+			parsed, err := contract.ParsedABI()
+			if err != nil {
+				return nil, fmt.Errorf("solc: %s:%s: %w", file, name, err)
+			}
+			abis[name] = parsed
+			files[name] = file
+		}
+	}
+	return abis, nil
+}
+
+// CompileNoCache is like [Compiler.Compile] but always runs solc, even if the
+// output is already cached. The cache is updated with the new output.
+func (c *Compiler) CompileNoCache(dir, contract string, outputSelection map[string]map[string][]string, opts ...Option) (map[string]map[string]Contract, error) {
+	opts = append(slices.Clip(opts), func(s *Settings) { s.noCache = true })
+	return c.Compile(dir, contract, outputSelection, opts...)
+}
+
+// CompileContext is like [Compiler.Compile] but kills the solc process if the
+// context is canceled or its deadline is exceeded before the compilation
+// completes.
+func (c *Compiler) CompileContext(ctx context.Context, dir, contract string, outputSelection map[string]map[string][]string, opts ...Option) (map[string]map[string]Contract, error) {
+	contracts, _, err := c.compileWithDiagnostics(ctx, dir, contract, outputSelection, opts)
+	return contracts, err
+}
+
+// CompileWithDiagnostics is like [Compiler.Compile] but additionally returns
+// all diagnostics (errors, warnings and infos) reported by solc. Diagnostics are
+// also returned if the compilation failed.
+func (c *Compiler) CompileWithDiagnostics(dir, contract string, outputSelection map[string]map[string][]string, opts ...Option) (map[string]map[string]Contract, []Diagnostic, error) {
+	return c.compileWithDiagnostics(context.Background(), dir, contract, outputSelection, opts)
+}
+
+// CompileWithMeta is like [Compiler.Compile] but additionally returns metadata
+// of the compilation, e.g. whether the output was served from the cache.
+func (c *Compiler) CompileWithMeta(dir, contract string, outputSelection map[string]map[string][]string, opts ...Option) (map[string]map[string]Contract, Meta, error) {
+	// build settings
+	s, err := c.buildSettings(outputSelection, opts)
+	if err != nil {
+		return nil, Meta{}, err
+	}
+
+	out, err := c.compile(context.Background(), dir, s)
+	if err != nil {
+		return nil, Meta{}, err
+	}
+	meta := Meta{FromCache: out.FromCache, Timing: out.Timing, Invocation: out.Invocation}
+	contracts, _, err := out.result(s, contract)
+	return contracts, meta, err
+}
+
+func (c *Compiler) compileWithDiagnostics(ctx context.Context, dir, contract string, outputSelection map[string]map[string][]string, opts []Option) (map[string]map[string]Contract, []Diagnostic, error) {
+	// build settings
+	s, err := c.buildSettings(outputSelection, opts)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	out, err := c.compile(ctx, dir, s)
+	if err != nil {
+		return nil, nil, err
+	}
+	return out.result(s, contract)
+}
+
+// CompileFile is like [Compiler.Compile] but only compiles the source file at
+// the given path. Imports are resolved relative to the directory of the file.
+func (c *Compiler) CompileFile(path, contract string, outputSelection map[string]map[string][]string, opts ...Option) (map[string]map[string]Contract, error) {
+	// build settings
+	s, err := c.buildSettings(outputSelection, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// check the file exists
+	absPath, err := filepath.Abs(path)
+	if err != nil {
+		return nil, err
+	}
+	if stat, err := os.Stat(absPath); err != nil || stat.IsDir() {
+		if err != nil {
+			return nil, err
+		}
+		return nil, fmt.Errorf("%s is a directory", path)
+	}
+
+	// build src map
+	srcMap := map[string]src{
+		filepath.Base(absPath): {URLS: []string{absPath}},
+	}
+
+	out, err := c.compileSrcMap(context.Background(), filepath.Dir(absPath), srcMap, s)
+	if err != nil {
+		return nil, err
+	}
+	contracts, _, err := out.result(s, contract)
+	return contracts, err
+}
+
+// CompileOutput is like [Compiler.Compile] but returns the complete [Output]
+// of solc, including the per-source outputs such as the AST. The output is also
+// returned if the compilation failed.
+//
+// To select the AST of all source files, select "ast" for the empty contract
+// name:
+//
+//	map[string]map[string][]string{"*": {"": {"ast"}}}
+func (c *Compiler) CompileOutput(dir string, outputSelection map[string]map[string][]string, opts ...Option) (*Output, error) {
+	// build settings
+	s, err := c.buildSettings(outputSelection, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	out, err := c.compile(context.Background(), dir, s)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := out.result(s, ""); err != nil {
+		return out, err
+	}
+	return out, nil
+}
+
+// BuildInput returns the standard JSON input that [Compiler.Compile] would
+// pass to solc for the sources in the given directory, without running solc.
+// The input can be piped into "solc --standard-json" to reproduce a
+// compilation.
+func (c *Compiler) BuildInput(dir string, outputSelection map[string]map[string][]string, opts ...Option) ([]byte, error) {
+	// build settings
+	s, err := c.buildSettings(outputSelection, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	absDir, srcMap, err := loadDir(dir, s.lang.ext())
+	if err != nil {
+		return nil, err
+	}
+	in, _, err := c.prepareInput(absDir, srcMap, s)
+	if err != nil {
+		return nil, err
+	}
+	return in.marshal()
+}
+
+// CompileStandardJSON runs solc with the given standard JSON input and returns
+// its raw standard JSON output. The input is passed to solc as is, so no
+// [Option] applies. Imports are resolved relative to the current working
+// directory.
+//
+// Outputs are cached like the outputs of [Compiler.Compile].
+func (c *Compiler) CompileStandardJSON(input []byte) ([]byte, error) {
+	if !json.Valid(input) {
+		return nil, fmt.Errorf("solc: invalid standard JSON input")
+	}
+	out, _, err := c.runRawWithCache(context.Background(), "", []string{"--standard-json"}, input, false)
+	if err != nil {
+		return nil, err
+	}
+	return bytes.Clone(out), nil
+}
+
+// CompileSources is like [Compiler.Compile] but compiles the given in-memory
+// sources instead of the sources in a directory. The sources map source file
+// names to their content.
+func (c *Compiler) CompileSources(sources map[string]string, contract string, outputSelection map[string]map[string][]string, opts ...Option) (map[string]map[string]Contract, error) {
+	// build settings
+	s, err := c.buildSettings(outputSelection, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// build src map
+	srcMap := make(map[string]src, len(sources))
+	for name, content := range sources {
+		srcMap[name] = src{Content: content}
+	}
+
+	out, err := c.compileSrcMap(context.Background(), "", srcMap, s)
+	if err != nil {
+		return nil, err
+	}
+	contracts, _, err := out.result(s, contract)
+	return contracts, err
+}
+
+// CompileFS is like [Compiler.Compile] but compiles the sources in the given
+// directory of a file system, e.g. an [embed.FS], instead of the sources in a
+// directory on disk. Sources are read into memory and named relative to root.
+func (c *Compiler) CompileFS(fsys fs.FS, root, contract string, outputSelection map[string]map[string][]string, opts ...Option) (map[string]map[string]Contract, error) {
+	// build settings
+	s, err := c.buildSettings(outputSelection, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	// build src map
+	srcMap := make(map[string]src)
+	err = fs.WalkDir(fsys, root, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || path.Ext(p) != s.lang.ext() {
+			return nil
+		}
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		name := p
+		if root != "." {
+			name = strings.TrimPrefix(p, root+"/")
+		}
+		srcMap[name] = src{Content: string(content)}
+		return nil
+	})
 	if err != nil {
 		return nil, err
 	}
+	if len(srcMap) == 0 {
+		return nil, fmt.Errorf("solc: no source files in %q", root)
+	}
 
-	// check for compilation errors
-	if err := out.Err(); err != nil {
+	out, err := c.compileSrcMap(context.Background(), "", srcMap, s)
+	if err != nil {
 		return nil, err
 	}
+	contracts, _, err := out.result(s, contract)
+	return contracts, err
+}
 
-	// find contract code
-	return out.Contracts, nil
+// CompileBatch compiles all given in-memory sources in a single solc
+// invocation, amortizing the startup of solc across the batch, and returns all
+// contracts keyed by their fully qualified name, e.g. "Token.sol:Token". The
+// sources map source file names to their content.
+//
+// Unlike [Compiler.CompileMany], sources of a batch are compiled together, so
+// they may import each other, and an error in any source fails the whole
+// batch.
+func (c *Compiler) CompileBatch(sources map[string]string, outputSelection map[string]map[string][]string, opts ...Option) (map[string]Contract, error) {
+	contracts, err := c.CompileSources(sources, "", outputSelection, opts...)
+	if err != nil {
+		return nil, err
+	}
 
+	batch := make(map[string]Contract)
+	for file, fileContracts := range contracts {
+		for name, contract := range fileContracts {
+			batch[file+":"+name] = contract
+		}
+	}
+	return batch, nil
 }
 
 // MustCompile is like [Compiler.Compile] but panics on error.
@@ -77,130 +481,294 @@ func (c *Compiler) MustCompile(dir, contract string, outputSelection map[string]
 }
 
 // compile
-func (c *Compiler) compile(baseDir string, outputSelection map[string]map[string][]string, opts []Option) (*output, error) {
+func (c *Compiler) compile(ctx context.Context, baseDir string, s *Settings) (*Output, error) {
+	absDir, srcMap, err := loadDir(baseDir, s.lang.ext())
+	if err != nil {
+		return nil, err
+	}
+	return c.compileSrcMap(ctx, absDir, srcMap, s)
+}
 
+// loadDir returns the absolute path of the given directory and the src map of
+// all sources with the given file extension in it.
+func loadDir(dir, ext string) (string, map[string]src, error) {
 	// check the directory exists
-	if stat, err := os.Stat(baseDir); err != nil || !stat.IsDir() {
+	if stat, err := os.Stat(dir); err != nil || !stat.IsDir() {
 		if err != nil {
-			return nil, err
+			return "", nil, err
 		}
-		return nil, fmt.Errorf("%s is not a directory", baseDir)
+		return "", nil, fmt.Errorf("%s is not a directory", dir)
 	}
 
 	// get absolute path of base directory
-	absDir, err := filepath.Abs(baseDir)
+	absDir, err := filepath.Abs(dir)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
 
 	// build src map
-	srcMap, err := buildSrcMap(absDir)
+	srcMap, err := buildSrcMap(absDir, ext)
 	if err != nil {
-		return nil, err
+		return "", nil, err
 	}
+	return absDir, srcMap, nil
+}
 
-	// add console.sol to src map
-	srcMap["console.sol"] = src{
-		Content: console.Src,
+// compileSrcMap compiles the given sources. The base directory is the
+// directory solc is allowed to read sources from. It may be empty if all
+// sources are given by content.
+func (c *Compiler) compileSrcMap(ctx context.Context, baseDir string, srcMap map[string]src, s *Settings) (*Output, error) {
+	in, contents, err := c.prepareInput(baseDir, srcMap, s)
+	if err != nil {
+		return nil, err
 	}
 
-	// build settings
-	s, err := c.buildSettings(outputSelection, opts)
+	out, err := c.runWithCache(ctx, baseDir, in)
 	if err != nil {
 		return nil, err
 	}
-	in := &input{
-		Lang:     s.lang,
-		Sources:  srcMap,
-		Settings: s,
+	if s.returnSources {
+		if err := out.setSourceContents(contents); err != nil {
+			return nil, err
+		}
 	}
 
-	// run solc
-	return c.runWithCache(absDir, in)
+	// directories solc looks up imports in, starting with the base path
+	out.normalizePaths(lookupDirs(baseDir, s.basePath, s.includePaths)[0])
+	return out, nil
 }
 
-func (c *Compiler) runWithCache(baseDir string, in *input) (*output, error) {
-	// hash input
-	h := sha256.New()
-	if err := json.NewEncoder(h).Encode(in); err != nil {
-		return nil, err
+// prepareInput checks the given sources and returns the standard JSON input
+// solc is run with to compile them. If the sources are returned, see
+// [WithReturnSources], the content of the sources and of the files they import
+// is returned as well, keyed by source unit name.
+func (c *Compiler) prepareInput(baseDir string, srcMap map[string]src, s *Settings) (*input, map[string]string, error) {
+	if s.lang == langSolidity {
+		if err := checkPragmas(baseDir, srcMap, c.version); err != nil {
+			return nil, nil, err
+		}
 	}
-	var hash [32]byte
-	h.Sum(hash[:0])
 
-	// run with cache
-	cacheKey := fmt.Sprintf("%s_%x", c.version, hash)
-	out, err, _ := group.Do(cacheKey, func() (any, error) {
-		// check cache
-		cacheMux.RLock()
-		val, ok := cache[cacheKey]
-		cacheMux.RUnlock()
-		if ok {
-			return val.out, val.err
+	// directories solc looks up imports in, starting with the base path
+	lookupDirs := lookupDirs(baseDir, s.basePath, s.includePaths)
+
+	in := buildInput(srcMap, s)
+	if s.noFilesystem {
+		// check the sources of the input, which include console.sol
+		if err := checkImports(in.Sources, s.Remappings); err != nil {
+			return nil, nil, err
+		}
+	}
+	if s.lang == langSolidity && !s.noFilesystem && baseDir != "" {
+		if remappings := nodeModulesRemappings(baseDir, srcMap, s.Remappings, lookupDirs); len(remappings) > 0 {
+			// don't modify the settings of the caller
+			settings := *s
+			settings.Remappings = append(slices.Clip(s.Remappings), remappings...)
+			in.Settings = &settings
+		}
+	}
+	if s.checkImports && !s.noFilesystem {
+		if err := checkFilesystemImports(baseDir, srcMap, in.Settings.Remappings, lookupDirs); err != nil {
+			return nil, nil, err
 		}
+	}
 
-		// run solc
-		out, err := c.run(baseDir, in)
+	var contents map[string]string
+	if s.returnSources {
+		// pass the sources by content and read the files they import before
+		// running solc, so the returned contents are the compiled ones
+		var err error
+		if in.Sources, contents, err = readSources(baseDir, in.Sources, in.Settings.Remappings, lookupDirs); err != nil {
+			return nil, nil, err
+		}
+	}
+	return in, contents, nil
+}
 
-		// update cache
-		cacheMux.Lock()
-		cache[cacheKey] = cacheItem{out, err}
-		cacheMux.Unlock()
+// readSources returns the given sources with their URLs replaced by their
+// content, and the content of the sources and of the files they import, see
+// [importClosure], keyed by source unit name.
+func readSources(baseDir string, srcMap map[string]src, remappings, lookupDirs []string) (map[string]src, map[string]string, error) {
+	sources := make(map[string]src, len(srcMap))
+	contents := make(map[string]string, len(srcMap))
+	for name, source := range srcMap {
+		source = readSource(baseDir, source)
+		if len(source.URLS) > 0 {
+			return nil, nil, fmt.Errorf("solc: failed to read source %q", name)
+		}
+		sources[name] = source
+		contents[name] = source.Content
+	}
 
-		return out, err
-	})
+	imported, _ := importClosure(baseDir, sources, remappings, lookupDirs)
+	maps.Copy(contents, imported)
+	return sources, contents, nil
+}
+
+// consoleFile is the name of the source of the console contracts that is added
+// to the sources of each Solidity compilation.
+const consoleFile = "console.sol"
+
+// buildInput returns the standard JSON input for the given sources and
+// settings.
+func buildInput(srcMap map[string]src, s *Settings) *input {
+	// add console.sol to src map
+	if s.lang == langSolidity {
+		srcMap[consoleFile] = src{
+			Content: console.Src,
+		}
+	}
+
+	return &input{
+		Lang:     s.lang,
+		Sources:  srcMap,
+		Settings: s,
+	}
+}
+
+func (c *Compiler) runWithCache(ctx context.Context, baseDir string, in *input) (*Output, error) {
+	inputJSON, err := in.marshal()
 	if err != nil {
 		return nil, err
 	}
-	return out.(*output), nil
-}
 
-func (c *Compiler) run(baseDir string, in *input) (*output, error) {
-	inputBuf := bytes.NewBuffer(nil)
-	outputBuf := bytes.NewBuffer(nil)
+	dir, args := baseDir, solcArgs(baseDir, in.Settings)
+	if in.Settings.noFilesystem {
+		// run solc in an empty directory, such that relative imports can't
+		// be resolved from disk
+		if dir, err = c.makeTempDir(); err != nil {
+			return nil, err
+		}
+		defer os.RemoveAll(dir)
+	}
 
-	// encode input
-	if err := json.NewEncoder(inputBuf).Encode(in); err != nil {
+	if timeout := in.Settings.timeout; timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeoutCause(ctx, timeout, errTimeout)
+		defer cancel()
+	}
+
+	out, stats, err := c.runRawWithCache(ctx, dir, args, inputJSON, in.Settings.noCache)
+	if err != nil {
 		return nil, err
 	}
 
-	var allowPaths []string
-	allowPaths = append(allowPaths, baseDir)
+	// decode output
+	start := time.Now()
+	var output *Output
+	if err := json.Unmarshal(out, &output); err != nil {
+		return nil, err
+	}
+	output.Raw = bytes.Clone(out) // the output may be shared by the cache
+	if err := output.setPlaceholderLinkReferences(); err != nil {
+		return nil, err
+	}
+	output.FromCache = stats.fromCache
+	output.Invocation = Invocation{
+		Args:      append([]string{c.solcAbsPath}, args...),
+		InputHash: sha256.Sum256(inputJSON),
+	}
+	if !in.Settings.noFilesystem {
+		// the empty directory is removed on return
+		output.Invocation.Dir = dir
+	}
+	output.Timing = Timing{
+		SolcDuration:  stats.solcDuration,
+		ParseDuration: time.Since(start),
+	}
+	if c.downloadDuration > 0 && c.downloadReported.CompareAndSwap(false, true) {
+		// the download is only reported once, with the first output
+		output.Timing.DownloadDuration = c.downloadDuration
+	}
+	return output, nil
+}
 
-	for _, remap := range in.Settings.Remappings {
-		parts := strings.Split(remap, "=")
-		if len(parts) != 2 {
-			//invalid remapping
-			continue
-		}
-		allowPaths = append(allowPaths, parts[1])
+// run runs solc with the given arguments in the given directory, passes the
+// standard JSON input to its stdin and returns its stdout.
+func (c *Compiler) run(ctx context.Context, dir string, args []string, inputJSON []byte) ([]byte, error) {
+	if c.isSolJS() {
+		return c.runSolJS(ctx, dir, inputJSON)
 	}
 
+	outputBuf := bytes.NewBuffer(nil)
+
 	// run solc
-	ex := exec.Command(c.solcAbsPath,
-		"--allow-paths", strings.Join(allowPaths, ","),
-		"--standard-json",
-	)
-	ex.Stdin = inputBuf
+	ex := exec.CommandContext(ctx, c.solcAbsPath, args...)
+	ex.Dir = dir // resolve imports relative to the base directory
+	ex.Stdin = bytes.NewReader(inputJSON)
 	ex.Stdout = outputBuf
-	if err := ex.Run(); err != nil {
+
+	c.log().Debug("running solc", "path", c.solcAbsPath, "args", args, "dir", dir)
+	start := time.Now()
+	err := ex.Run()
+	c.log().Debug("solc finished", "duration", time.Since(start), "err", err)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("solc %s: %w", c.version, context.Cause(ctx))
+		}
 		return nil, err
 	}
+	return outputBuf.Bytes(), nil
+}
 
-	// decode output
-	var output *output
-	if err := json.NewDecoder(outputBuf).Decode(&output); err != nil {
-		return nil, err
+// solcArgs returns the command line arguments to run solc with.
+func solcArgs(baseDir string, s *Settings) []string {
+	if s.noFilesystem {
+		return append([]string{"--standard-json"}, s.extraArgs...)
 	}
-	return output, nil
+
+	var args []string
+	if s.basePath != "" {
+		args = append(args, "--base-path", s.basePath)
+	}
+	for _, includePath := range s.includePaths {
+		args = append(args, "--include-path", includePath)
+	}
+	if paths := allowPaths(baseDir, s); len(paths) > 0 {
+		args = append(args, "--allow-paths", strings.Join(paths, ","))
+	}
+	args = append(args, "--standard-json")
+	return append(args, s.extraArgs...)
+}
+
+// allowPaths returns the paths solc is allowed to read sources from: the base
+// directory (if any), the base path and include paths and either the target of
+// each remapping or, if set, the allowed paths of the settings.
+func allowPaths(baseDir string, s *Settings) []string {
+	var paths []string
+	if baseDir != "" {
+		paths = append(paths, baseDir)
+	}
+	if s.basePath != "" {
+		paths = append(paths, s.basePath)
+	}
+	paths = append(paths, s.includePaths...)
+	if s.restrictPaths {
+		return append(paths, s.allowedPaths...)
+	}
+	root := lookupDirs(baseDir, s.basePath, nil)[0]
+	for _, remap := range s.Remappings {
+		_, target, ok := strings.Cut(remap, "=")
+		if !ok || target == "" {
+			// invalid remapping
+			continue
+		}
+		if baseDir != "" && !filepath.IsAbs(filepath.FromSlash(target)) {
+			// relative targets are source unit names, which solc resolves
+			// against the base path
+			target = filepath.Join(root, filepath.FromSlash(target))
+		}
+		paths = append(paths, target)
+	}
+	return paths
 }
 
-func buildSrcMap(absDir string) (map[string]src, error) {
+func buildSrcMap(absDir, ext string) (map[string]src, error) {
 	fsys := os.DirFS(absDir)
 
 	srcMap := make(map[string]src)
 	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
-		if d.IsDir() || filepath.Ext(p) != ".sol" {
+		if d.IsDir() || filepath.Ext(p) != ext {
 			return nil
 		}
 		srcMap[p] = src{
@@ -230,9 +798,103 @@ func (c *Compiler) buildSettings(outputSelection map[string]map[string][]string,
 	for _, opt := range opts {
 		opt(s)
 	}
-	s.OutputSelection = outputSelection
-	if outputSelection == nil {
+	if !s.lang.isValid() {
+		return nil, fmt.Errorf("solc: unknown language %q", s.lang)
+	}
+	if !s.EVMVersion.isValid() {
+		return nil, fmt.Errorf("solc: unknown EVM version %q", s.EVMVersion)
+	}
+	if s.Metadata != nil && s.Metadata.BytecodeHash != "" && !s.Metadata.BytecodeHash.isValid() {
+		return nil, fmt.Errorf("solc: unknown metadata bytecode hash %q", s.Metadata.BytecodeHash)
+	}
+	if len(s.includePaths) > 0 && s.basePath == "" {
+		return nil, fmt.Errorf("solc: include paths require a base path")
+	}
+	if err := c.checkVersionSupport(s); err != nil {
+		return nil, err
+	}
+	if s.StopAfter != "" && s.StopAfter != StopAfterParsing {
+		return nil, fmt.Errorf("solc: unknown stop after stage %q", s.StopAfter)
+	}
+	if err := validateLibraries(s.Libraries); err != nil {
+		return nil, err
+	}
+	if outputSelection != nil {
+		s.OutputSelection = outputSelection
+	} else if s.OutputSelection == nil && s.StopAfter != "" {
+		// solc rejects outputs that require code generation
+		s.OutputSelection = OutputArtifacts(ArtifactAST)
+	} else if s.OutputSelection == nil {
 		s.OutputSelection = DefaultOutputSelection
 	}
+	if err := validateOutputSelection(s.OutputSelection); err != nil {
+		return nil, err
+	}
 	return s, nil
 }
+
+// checkVersionSupport checks that the solc version of the compiler supports
+// all features used by the given settings.
+func (c *Compiler) checkVersionSupport(s *Settings) error {
+	require := func(feature string, min Version) error {
+		if c.version.Cmp(min) < 0 {
+			return fmt.Errorf("solc: %s requires solc %s or later, got %s", feature, min, c.version)
+		}
+		return nil
+	}
+
+	if err := require(fmt.Sprintf("EVM version %q", s.EVMVersion), evmVersions[s.EVMVersion]); err != nil {
+		return err
+	}
+	if s.ViaIR {
+		if err := require("viaIR", minViaIRVersion); err != nil {
+			return err
+		}
+	}
+	if s.Optimizer != nil && s.Optimizer.Details != nil {
+		if err := require("optimizer details", minOptimizerDetailsVersion); err != nil {
+			return err
+		}
+		if s.Optimizer.Details.YulDetails != nil {
+			if err := require("Yul optimizer details", minYulDetailsVersion); err != nil {
+				return err
+			}
+		}
+	}
+	if s.Metadata != nil && s.Metadata.BytecodeHash != "" {
+		if err := require("metadata bytecode hash", minBytecodeHashVersion); err != nil {
+			return err
+		}
+	}
+	if len(s.includePaths) > 0 {
+		if err := require("include paths", minIncludePathVersion); err != nil {
+			return err
+		}
+	}
+	if s.ParserErrorRecovery && c.version.Cmp(maxErrorRecoveryVersion) > 0 {
+		return fmt.Errorf("solc: parser error recovery requires solc %s or earlier, got %s", maxErrorRecoveryVersion, c.version)
+	}
+	return nil
+}
+
+// validateLibraries checks that all library addresses are 0x-prefixed 20 byte
+// hex strings.
+func validateLibraries(libs map[string]map[string]string) error {
+	for _, file := range slices.Sorted(maps.Keys(libs)) {
+		for _, name := range slices.Sorted(maps.Keys(libs[file])) {
+			addr := libs[file][name]
+			if !isHexAddress(addr) {
+				return fmt.Errorf("solc: invalid address %q for library %s:%s", addr, file, name)
+			}
+		}
+	}
+	return nil
+}
+
+func isHexAddress(s string) bool {
+	if len(s) != 42 || !strings.HasPrefix(s, "0x") {
+		return false
+	}
+	_, err := hex.DecodeString(s[2:])
+	return err == nil
+}
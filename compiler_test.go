@@ -1,13 +1,26 @@
 package solc
 
 import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"errors"
 	"fmt"
 	"io/ioutil"
+	"log/slog"
+	"maps"
 	"os"
 	"os/exec"
 	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
 	"testing"
+	"testing/fstest"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
 )
 
 func TestCompile(t *testing.T) {
@@ -30,8 +43,8 @@ func TestCompile(t *testing.T) {
 	for _, c := range contract {
 		for name, a := range c {
 			fmt.Printf("Contract: %s, ABI: %s\n", name, a.ABI)
-			fmt.Printf("Bytecode: %s\n", a.EVM.Bytecode.Object)
-			fmt.Printf("Deployed Bytecode: %s\n", a.EVM.DeployedBytecode.Object)
+			fmt.Printf("Bytecode: %x\n", a.EVM.Bytecode.Object)
+			fmt.Printf("Deployed Bytecode: %x\n", a.EVM.DeployedBytecode.Object)
 
 		}
 	}
@@ -189,3 +202,1001 @@ func TestDummySolcExecution(t *testing.T) {
 		t.Errorf("unexpected dummy solc output: %s", out)
 	}
 }
+
+// newFakeCompiler returns a [Compiler] that uses a fake solc executable, which
+// emits the given standard JSON output. The standard JSON input of the last
+// invocation can be read using [fakeInput].
+func newFakeCompiler(t *testing.T, output string) *Compiler {
+	t.Helper()
+
+	solcPath := filepath.Join(t.TempDir(), "solc")
+	script := "#!/bin/sh\ncat > \"$(dirname \"$0\")/input.json\"\ncat <<'EOF'\n" + output + "\nEOF\n"
+	if err := os.WriteFile(solcPath, []byte(script), 0o755); err != nil {
+		t.Fatalf("failed to write fake solc: %v", err)
+	}
+
+	// outputs of fake solc executables differ for the same input, so they
+	// must not be served from the cache
+	cacheMux.Lock()
+	clear(cache)
+	cacheMux.Unlock()
+
+	return &Compiler{version: VersionLatest, solcAbsPath: solcPath}
+}
+
+// fakeInput returns the standard JSON input of the last invocation of the fake
+// solc executable of the given [Compiler].
+func fakeInput(t *testing.T, c *Compiler) map[string]any {
+	t.Helper()
+
+	data, err := os.ReadFile(filepath.Join(filepath.Dir(c.solcAbsPath), "input.json"))
+	if err != nil {
+		t.Fatalf("failed to read fake solc input: %v", err)
+	}
+	var in map[string]any
+	if err := json.Unmarshal(data, &in); err != nil {
+		t.Fatalf("failed to decode fake solc input: %v", err)
+	}
+	return in
+}
+
+func TestCompileWithDiagnostics(t *testing.T) {
+	warning := `{"severity": "warning", "type": "Warning", "component": "general", "errorCode": "2072", ` +
+		`"message": "Unused local variable.", "formattedMessage": "Warning: Unused local variable.", ` +
+		`"sourceLocation": {"file": "Test.sol", "start": 10, "end": 20}}`
+	error_ := `{"severity": "error", "type": "TypeError", "component": "general", "errorCode": "7407", ` +
+		`"message": "Type mismatch.", "formattedMessage": "TypeError: Type mismatch."}`
+
+	t.Run("warning", func(t *testing.T) {
+		c := newFakeCompiler(t, `{"errors": [`+warning+`], "contracts": {"Test.sol": {"Test": {}}}}`)
+		srcDir := t.TempDir()
+		createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+		contracts, diags, err := c.CompileWithDiagnostics(srcDir, "Test", nil)
+		if err != nil {
+			t.Fatalf("Compile failed: %v", err)
+		}
+		if len(contracts) != 1 {
+			t.Fatalf("want 1 contract, got %d", len(contracts))
+		}
+
+		want := []Diagnostic{{
+			SourceLocation:   SourceLocation{File: "Test.sol", Start: 10, End: 20},
+			Type:             "Warning",
+			Component:        "general",
+			Severity:         "warning",
+			ErrorCode:        "2072",
+			Message:          "Unused local variable.",
+			FormattedMessage: "Warning: Unused local variable.",
+		}}
+		if diff := cmp.Diff(want, diags); diff != "" {
+			t.Fatalf("(-want +got)\n%s", diff)
+		}
+	})
+
+	t.Run("error", func(t *testing.T) {
+		c := newFakeCompiler(t, `{"errors": [`+warning+`, `+error_+`]}`)
+		srcDir := t.TempDir()
+		createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+		_, diags, err := c.CompileWithDiagnostics(srcDir, "Test", nil)
+		if err == nil {
+			t.Fatal("want compilation error")
+		}
+		if len(diags) != 2 {
+			t.Fatalf("want 2 diagnostics, got %d", len(diags))
+		}
+
+		// the error only holds the diagnostics that caused the failure
+		var compileErr *CompileError
+		if !errors.As(err, &compileErr) {
+			t.Fatalf("want *CompileError, got %T", err)
+		}
+		if diff := cmp.Diff(diags[1:], compileErr.Diagnostics); diff != "" {
+			t.Fatalf("(-want +got)\n%s", diff)
+		}
+		if want := "solc: compilation failed\nTypeError: Type mismatch."; err.Error() != want {
+			t.Fatalf("want error %q, got %q", want, err.Error())
+		}
+
+		// helpers filter all diagnostics by severity
+		if diff := cmp.Diff(diags, compileErr.All); diff != "" {
+			t.Fatalf("All (-want +got)\n%s", diff)
+		}
+		if diff := cmp.Diff(diags[1:], compileErr.Errors()); diff != "" {
+			t.Fatalf("Errors (-want +got)\n%s", diff)
+		}
+		if diff := cmp.Diff(diags[:1], compileErr.Warnings()); diff != "" {
+			t.Fatalf("Warnings (-want +got)\n%s", diff)
+		}
+		if first := compileErr.FirstError(); first == nil || first.Type != "TypeError" {
+			t.Fatalf("want first error TypeError, got %v", first)
+		}
+	})
+}
+
+func TestCompileStrictWarnings(t *testing.T) {
+	info := `{"severity": "info", "type": "Info", "formattedMessage": "Info: Some info."}`
+	warning := `{"severity": "warning", "type": "Warning", "formattedMessage": "Warning: Unused local variable."}`
+
+	t.Run("warning", func(t *testing.T) {
+		c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}, "errors": [`+info+`, `+warning+`]}`)
+		srcDir := t.TempDir()
+		createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+		if _, err := c.Compile(srcDir, "Test", nil); err != nil {
+			t.Fatalf("Compile without strict warnings failed: %v", err)
+		}
+
+		_, err := c.Compile(srcDir, "Test", nil, WithStrictWarnings())
+		if err == nil || !strings.Contains(err.Error(), "Unused local variable") {
+			t.Fatalf("want error listing the warning, got %v", err)
+		}
+		if strings.Contains(err.Error(), "Some info") {
+			t.Fatalf("want error not to list infos, got %v", err)
+		}
+	})
+
+	t.Run("info", func(t *testing.T) {
+		c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}, "errors": [`+info+`]}`)
+		srcDir := t.TempDir()
+		createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+		if _, err := c.Compile(srcDir, "Test", nil, WithStrictWarnings()); err != nil {
+			t.Fatalf("Compile failed: %v", err)
+		}
+	})
+}
+
+func TestCompileSources(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Gen.sol": {"Gen": {}}}}`)
+
+	sources := map[string]string{
+		"Gen.sol": "pragma solidity ^0.8.0; contract Gen {}",
+	}
+	contracts, err := c.CompileSources(sources, "Gen", nil)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if _, ok := contracts["Gen.sol"]["Gen"]; !ok {
+		t.Fatalf("want contract Gen.sol:Gen, got %v", contracts)
+	}
+
+	in := fakeInput(t, c)
+	gotContent := in["sources"].(map[string]any)["Gen.sol"].(map[string]any)["content"]
+	if sources["Gen.sol"] != gotContent {
+		t.Fatalf("want source content %q, got %q", sources["Gen.sol"], gotContent)
+	}
+}
+
+func TestCompileContextCanceled(t *testing.T) {
+	c := newFakeCompiler(t, `{}`)
+	if err := os.WriteFile(c.solcAbsPath, []byte("#!/bin/sh\nexec sleep 10\n"), 0o755); err != nil {
+		t.Fatalf("failed to write fake solc: %v", err)
+	}
+
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	ctx, cancel := context.WithTimeout(context.Background(), 100*time.Millisecond)
+	defer cancel()
+
+	start := time.Now()
+	_, err := c.CompileContext(ctx, srcDir, "Test", nil)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("want deadline exceeded error, got %v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("want solc to be killed, took %s", d)
+	}
+}
+
+func TestCompileOutput(t *testing.T) {
+	c := newFakeCompiler(t, `{"sources": {"Test.sol": {"id": 0, "ast": {"nodeType": "SourceUnit"}}}}`)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	out, err := c.CompileOutput(srcDir, map[string]map[string][]string{"*": {"": {"ast"}}})
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+
+	src, ok := out.Sources["Test.sol"]
+	if !ok {
+		t.Fatalf("want source output for Test.sol, got %v", out.Sources)
+	}
+	if want := `{"nodeType": "SourceUnit"}`; want != string(src.AST) {
+		t.Fatalf("want AST %s, got %s", want, src.AST)
+	}
+}
+
+func TestCompileFile(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}}`)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", `pragma solidity ^0.8.0; import "./Lib.sol";`)
+	createDummyContract(t, srcDir, "Lib", "pragma solidity ^0.8.0;")
+
+	contracts, err := c.CompileFile(filepath.Join(srcDir, "Test.sol"), "Test", nil)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if _, ok := contracts["Test.sol"]["Test"]; !ok {
+		t.Fatalf("want contract Test.sol:Test, got %v", contracts)
+	}
+
+	// only the given file (and console.sol) is passed to solc
+	in := fakeInput(t, c)
+	sources := in["sources"].(map[string]any)
+	if _, ok := sources["Lib.sol"]; ok || len(sources) != 2 {
+		t.Fatalf("want only Test.sol and console.sol, got %v", sources)
+	}
+}
+
+func TestCompileAll(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"A.sol": {"A": {}, "B": {}}, "C.sol": {"C": {}}}}`)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "A", "pragma solidity ^0.8.0;")
+	createDummyContract(t, srcDir, "C", "pragma solidity ^0.8.0;")
+
+	all, err := c.CompileAll(srcDir, nil)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if len(all["A.sol"]) != 2 || len(all["C.sol"]) != 1 {
+		t.Fatalf("want all contracts, got %v", all)
+	}
+
+	named, err := c.Compile(srcDir, "B", nil)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if _, ok := named["A.sol"]["B"]; !ok || len(named) != 1 || len(named["A.sol"]) != 1 {
+		t.Fatalf("want only contract A.sol:B, got %v", named)
+	}
+}
+
+func TestNewOffline(t *testing.T) {
+	binPath := t.TempDir()
+	for _, v := range []string{"0.8.19", "0.8.20"} {
+		if err := os.WriteFile(filepath.Join(binPath, "solc_v"+v), nil, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	t.Run("installed", func(t *testing.T) {
+		c, err := New("0.8.19", binPath, WithOffline())
+		if err != nil {
+			t.Fatalf("Failed to create compiler: %v", err)
+		}
+		if want := filepath.Join(binPath, "solc_v0.8.19"); want != c.BinaryPath() {
+			t.Fatalf("want solc path %q, got %q", want, c.BinaryPath())
+		}
+	})
+
+	t.Run("constraint", func(t *testing.T) {
+		c, err := New("^0.8.0", binPath, WithOffline())
+		if err != nil {
+			t.Fatalf("Failed to create compiler: %v", err)
+		}
+		if want := Version("0.8.20"); want != c.Version() {
+			t.Fatalf("want version %q, got %q", want, c.Version())
+		}
+	})
+
+	t.Run("not_installed", func(t *testing.T) {
+		_, err := New("0.8.21", binPath, WithOffline())
+		if err == nil || !strings.Contains(err.Error(), "not installed") {
+			t.Fatalf("want not installed error, got %v", err)
+		}
+	})
+}
+
+func TestBuildInput(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}}`)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	data, err := c.BuildInput(srcDir, nil, WithEVMVersion(EVMVersionParis))
+	if err != nil {
+		t.Fatalf("Failed to build input: %v", err)
+	}
+	var got map[string]any
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to decode input: %v", err)
+	}
+
+	// the built input matches the input passed to solc
+	if _, err := c.Compile(srcDir, "Test", nil, WithEVMVersion(EVMVersionParis)); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if diff := cmp.Diff(fakeInput(t, c), got); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+	if evmVersion := got["settings"].(map[string]any)["evmVersion"]; evmVersion != "paris" {
+		t.Fatalf("want evmVersion paris, got %v", evmVersion)
+	}
+}
+
+func TestCompileStandardJSON(t *testing.T) {
+	output := `{"contracts":{"Test.sol":{"Test":{"abi":[]}}}}`
+	c := newFakeCompiler(t, output)
+
+	input := `{"language":"Solidity","sources":{"Test.sol":{"content":"// ` + t.TempDir() + `"}}}`
+	got, err := c.CompileStandardJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	if strings.TrimSpace(string(got)) != output {
+		t.Fatalf("want output %s, got %s", output, got)
+	}
+
+	// the input is passed to solc as is
+	var want map[string]any
+	if err := json.Unmarshal([]byte(input), &want); err != nil {
+		t.Fatal(err)
+	}
+	if diff := cmp.Diff(want, fakeInput(t, c)); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+
+	if _, err := c.CompileStandardJSON([]byte(`{`)); err == nil {
+		t.Fatal("want error for invalid input, got nil")
+	}
+}
+
+func TestCompileSourcesNoFilesystem(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}}`)
+
+	t.Run("imports_resolved", func(t *testing.T) {
+		sources := map[string]string{
+			"Test.sol":    `import "./lib/Lib.sol"; contract Test {}`,
+			"lib/Lib.sol": `library Lib {}`,
+		}
+		if _, err := c.CompileSources(sources, "Test", nil, WithNoFilesystem()); err != nil {
+			t.Fatalf("Failed to compile: %v", err)
+		}
+	})
+
+	t.Run("import_console", func(t *testing.T) {
+		sources := map[string]string{
+			"Test.sol": `import "console.sol"; contract Test {}`,
+		}
+		if _, err := c.CompileSources(sources, "Test", nil, WithNoFilesystem()); err != nil {
+			t.Fatalf("Failed to compile: %v", err)
+		}
+	})
+
+	t.Run("import_from_disk", func(t *testing.T) {
+		sources := map[string]string{
+			"Test.sol": `import "/etc/passwd"; contract Test {}`,
+		}
+		_, err := c.CompileSources(sources, "Test", nil, WithNoFilesystem())
+		if err == nil || !strings.Contains(err.Error(), `"/etc/passwd"`) {
+			t.Fatalf("want error listing the import, got %v", err)
+		}
+	})
+
+	t.Run("directory", func(t *testing.T) {
+		srcDir := t.TempDir()
+		createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+		if _, err := c.Compile(srcDir, "Test", nil, WithNoFilesystem()); err == nil {
+			t.Fatal("want error for sources read from disk")
+		}
+	})
+}
+
+func TestLongVersion(t *testing.T) {
+	tests := []struct {
+		Version Version
+		Want    string
+	}{
+		{Version: "0.8.30", Want: "0.8.30+commit.73712a01"},
+		{Version: "0.0.1", Want: "0.0.1"},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := &Compiler{version: test.Version}
+			if got := c.LongVersion(); got != test.Want {
+				t.Fatalf("want %q, got %q", test.Want, got)
+			}
+		})
+	}
+}
+
+func TestCompileTimeout(t *testing.T) {
+	c := newFakeCompiler(t, `{}`)
+	if err := os.WriteFile(c.solcAbsPath, []byte("#!/bin/sh\nexec sleep 10\n"), 0o755); err != nil {
+		t.Fatalf("failed to write fake solc: %v", err)
+	}
+
+	sources := map[string]string{"Test.sol": "contract Test {}"}
+
+	start := time.Now()
+	_, err := c.CompileSources(sources, "Test", nil, WithTimeout(100*time.Millisecond), WithNoFilesystem())
+	if !errors.Is(err, context.DeadlineExceeded) || !strings.Contains(err.Error(), "timed out") {
+		t.Fatalf("want timeout error, got %v", err)
+	}
+	if d := time.Since(start); d > 5*time.Second {
+		t.Fatalf("want solc to be killed, took %s", d)
+	}
+
+	// timed out compilations are not cached
+	if entries := c.CacheStats().Entries; entries != 0 {
+		t.Fatalf("want 0 cache entries, got %d", entries)
+	}
+}
+
+func TestWithLogger(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}}`)
+
+	var buf bytes.Buffer
+	WithLogger(slog.New(slog.NewJSONHandler(&buf, &slog.HandlerOptions{Level: slog.LevelDebug})))(c)
+
+	sources := map[string]string{"Test.sol": "contract Test {}"}
+	for range 2 {
+		if _, err := c.CompileSources(sources, "Test", nil); err != nil {
+			t.Fatalf("Failed to compile: %v", err)
+		}
+	}
+
+	var msgs []string
+	dec := json.NewDecoder(&buf)
+	for dec.More() {
+		var record struct {
+			Msg string `json:"msg"`
+		}
+		if err := dec.Decode(&record); err != nil {
+			t.Fatalf("Failed to decode log record: %v", err)
+		}
+		msgs = append(msgs, record.Msg)
+	}
+
+	want := []string{"cache miss", "running solc", "solc finished", "cache hit"}
+	if diff := cmp.Diff(want, msgs); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
+
+func TestCompileInvocation(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}}`)
+	readInputHash := func(t *testing.T) [32]byte {
+		input, err := os.ReadFile(filepath.Join(filepath.Dir(c.solcAbsPath), "input.json"))
+		if err != nil {
+			t.Fatal(err)
+		}
+		return sha256.Sum256(input)
+	}
+
+	t.Run("dir", func(t *testing.T) {
+		srcDir := t.TempDir()
+		createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+		_, meta, err := c.CompileWithMeta(srcDir, "Test", nil)
+		if err != nil {
+			t.Fatalf("Failed to compile: %v", err)
+		}
+
+		want := Invocation{
+			Args:      []string{c.solcAbsPath, "--allow-paths", srcDir, "--standard-json"},
+			Dir:       srcDir,
+			InputHash: readInputHash(t),
+		}
+		if diff := cmp.Diff(want, meta.Invocation); diff != "" {
+			t.Fatalf("(-want +got)\n%s", diff)
+		}
+	})
+
+	t.Run("no_filesystem", func(t *testing.T) {
+		s, err := c.buildSettings(nil, []Option{WithNoFilesystem()})
+		if err != nil {
+			t.Fatal(err)
+		}
+		out, err := c.compileSrcMap(context.Background(), "", map[string]src{"Test.sol": {Content: "contract Test {}"}}, s)
+		if err != nil {
+			t.Fatalf("Failed to compile: %v", err)
+		}
+
+		want := Invocation{
+			Args:      []string{c.solcAbsPath, "--standard-json"},
+			InputHash: readInputHash(t),
+		}
+		if diff := cmp.Diff(want, out.Invocation); diff != "" {
+			t.Fatalf("(-want +got)\n%s", diff)
+		}
+	})
+}
+
+func TestCompileYul(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.yul": {"Test": {"evm": {"bytecode": {"object": "6001"}}}}}}`)
+
+	srcDir := t.TempDir()
+	yul := `object "Test" { code { sstore(0, 1) } }`
+	if err := os.WriteFile(filepath.Join(srcDir, "Test.yul"), []byte(yul), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	createDummyContract(t, srcDir, "Other", "pragma solidity ^0.8.0;")
+
+	contracts, err := c.Compile(srcDir, "Test", nil, WithLanguage("Yul"))
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	contract := contracts["Test.yul"]["Test"]
+	if contract.ABI != nil || string(contract.EVM.Bytecode.Object) != "\x60\x01" {
+		t.Fatalf("want Yul contract without ABI, got %+v", contract)
+	}
+
+	// only the Yul source is passed to solc
+	in := fakeInput(t, c)
+	if lang := in["language"]; lang != "Yul" {
+		t.Fatalf("want language Yul, got %v", lang)
+	}
+	sources := in["sources"].(map[string]any)
+	if _, ok := sources["Test.yul"]; !ok || len(sources) != 1 {
+		t.Fatalf("want only source Test.yul, got %v", sources)
+	}
+
+	if _, err := c.Compile(srcDir, "Test", nil, WithLanguage("Vyper")); err == nil {
+		t.Fatal("want error for unknown language")
+	}
+}
+
+// newSolcCompiler returns a [Compiler] for the latest solc version or skips the
+// test if solc is not installed and can't be downloaded.
+func newSolcCompiler(t *testing.T) *Compiler {
+	t.Helper()
+
+	c, err := New(VersionLatest, "./.solc")
+	if err != nil {
+		t.Skipf("solc not available: %v", err)
+	}
+	return c
+}
+
+func TestCompileImportGraph(t *testing.T) {
+	t.Run("sources", func(t *testing.T) {
+		c := newFakeCompiler(t, `{"contracts": {"test1.sol": {"Test1": {}}, "lib.sol": {"Lib": {}}}}`)
+
+		contracts, err := c.Compile("testdata/test1", "Test1", nil)
+		if err != nil {
+			t.Fatalf("Compile failed: %v", err)
+		}
+		if _, ok := contracts["test1.sol"]["Test1"]; !ok || len(contracts) != 1 {
+			t.Fatalf("want only contract Test1, got %v", contracts)
+		}
+
+		// the imported file is part of the input
+		sources := fakeInput(t, c)["sources"].(map[string]any)
+		for _, name := range []string{"test1.sol", "lib.sol"} {
+			if _, ok := sources[name]; !ok {
+				t.Fatalf("want source %s, got %v", name, sources)
+			}
+		}
+	})
+
+	t.Run("solc", func(t *testing.T) {
+		c := newSolcCompiler(t)
+
+		contracts, err := c.Compile("testdata/test1", "Test1", nil)
+		if err != nil {
+			t.Fatalf("Compile failed: %v", err)
+		}
+		if !contracts["test1.sol"]["Test1"].Deployable() {
+			t.Fatalf("want bytecode of Test1, got %v", contracts)
+		}
+	})
+}
+
+func TestCompileImportCheck(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}}`)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", `import "./Missing.sol";`)
+
+	_, err := c.Compile(srcDir, "Test", nil, WithImportCheck())
+	if err == nil || !strings.Contains(err.Error(), `Test.sol:1: "./Missing.sol"`) {
+		t.Fatalf("want unresolved import error, got %v", err)
+	}
+
+	// without the check, solc reports the error
+	if _, err := c.Compile(srcDir, "Test", nil); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+}
+
+func TestCompileIR(t *testing.T) {
+	c := newSolcCompiler(t)
+
+	contracts, err := c.Compile("src", "SimpleStorage", OutputArtifacts(ArtifactIR, ArtifactIROptimized))
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	if len(contracts) == 0 {
+		t.Fatal("want contract SimpleStorage")
+	}
+	for _, contract := range contracts {
+		if contract["SimpleStorage"].IR == "" || contract["SimpleStorage"].IROptimized == "" {
+			t.Fatal("want IR and optimized IR")
+		}
+	}
+}
+
+func TestCompileAssembly(t *testing.T) {
+	c := newSolcCompiler(t)
+
+	contracts, err := c.Compile("src", "SimpleStorage", OutputArtifacts(ArtifactAssembly))
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	if len(contracts) == 0 {
+		t.Fatal("want contract SimpleStorage")
+	}
+	for _, contract := range contracts {
+		if contract["SimpleStorage"].EVM.Assembly == "" {
+			t.Fatal("want assembly")
+		}
+	}
+}
+
+func TestCompileStopAfter(t *testing.T) {
+	c := newFakeCompiler(t, `{"sources": {"Test.sol": {"id": 0, "ast": {"nodeType": "SourceUnit"}}}}`)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	out, err := c.CompileOutput(srcDir, nil, WithStopAfter(StopAfterParsing))
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if len(out.Sources["Test.sol"].AST) == 0 {
+		t.Fatalf("want AST, got %v", out.Sources)
+	}
+	if got := fakeInput(t, c)["settings"].(map[string]any)["stopAfter"]; got != "parsing" {
+		t.Fatalf("want stopAfter %q, got %v", "parsing", got)
+	}
+}
+
+func TestCompileToABI(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"A.sol": {"A": {"abi": [{"type": "function", "name": "set", `+
+		`"inputs": [{"name": "x", "type": "uint256"}], "outputs": [], "stateMutability": "nonpayable"}]}, "B": {}}, `+
+		`"console.sol": {"console": {}}}}`)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "A", "pragma solidity ^0.8.0;")
+
+	abis, err := c.CompileToABI(srcDir, nil)
+	if err != nil {
+		t.Fatalf("CompileToABI failed: %v", err)
+	}
+	if _, ok := abis["A"].Methods["set"]; !ok || len(abis) != 2 {
+		t.Fatalf("want ABIs of A and B, got %v", abis)
+	}
+
+	sel, err := json.Marshal(fakeInput(t, c)["settings"].(map[string]any)["outputSelection"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := `{"*":{"*":["abi"]}}`; want != string(sel) {
+		t.Fatalf("want output selection %s, got %s", want, sel)
+	}
+
+	c = newFakeCompiler(t, `{"contracts": {"A.sol": {"A": {}}, "B.sol": {"A": {}}}}`)
+	if _, err := c.CompileToABI(srcDir, nil); err == nil || !strings.Contains(err.Error(), "A.sol and B.sol") {
+		t.Fatalf("want duplicate contract error, got %v", err)
+	}
+}
+
+func TestWithInputTransformer(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}}`)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	transform := WithInputTransformer(func(in map[string]any) map[string]any {
+		in["settings"].(map[string]any)["eofVersion"] = 1
+		return in
+	})
+	if _, err := c.Compile(srcDir, "Test", nil, transform); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if got := fakeInput(t, c)["settings"].(map[string]any)["eofVersion"]; got != 1.0 {
+		t.Fatalf("want eofVersion 1, got %v", got)
+	}
+
+	input, err := c.BuildInput(srcDir, nil, transform)
+	if err != nil {
+		t.Fatalf("BuildInput failed: %v", err)
+	}
+	if !strings.Contains(string(input), `"eofVersion":1`) {
+		t.Fatalf("want transformed input, got %s", input)
+	}
+}
+
+func TestCompileImmutableReferences(t *testing.T) {
+	c := newSolcCompiler(t)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Immutable", `// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+
+contract Immutable {
+    uint256 public immutable value;
+
+    constructor(uint256 v) {
+        value = v;
+    }
+}`)
+
+	contracts, err := c.Compile(srcDir, "Immutable", OutputArtifacts(ArtifactDeployedBytecode, ArtifactImmutableReferences))
+	if err != nil {
+		t.Fatalf("Failed to compile: %v", err)
+	}
+	refs := contracts["Immutable.sol"]["Immutable"].EVM.DeployedBytecode.ImmutableReferences
+	if len(refs) == 0 {
+		t.Fatal("want immutable references")
+	}
+	for id, locs := range refs {
+		for _, loc := range locs {
+			if loc.Length != 32 {
+				t.Fatalf("want 32 byte reference of %s, got %d", id, loc.Length)
+			}
+		}
+	}
+}
+
+func TestCompileOutputRaw(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {"evm": {"newField": 1}}}}, "newField": true}`)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	out, err := c.CompileOutput(srcDir, nil)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if _, ok := out.Contracts["Test.sol"]["Test"]; !ok {
+		t.Fatalf("want contract Test, got %v", out.Contracts)
+	}
+
+	var raw struct {
+		NewField bool `json:"newField"`
+	}
+	if err := json.Unmarshal(out.Raw, &raw); err != nil {
+		t.Fatalf("Failed to decode raw output: %v", err)
+	}
+	if !raw.NewField {
+		t.Fatalf("want newField in raw output, got %s", out.Raw)
+	}
+}
+
+func TestCompileOutputTiming(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}}`)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	out, err := c.CompileOutput(srcDir, nil)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if out.Timing.SolcDuration <= 0 || out.Timing.DownloadDuration != 0 {
+		t.Fatalf("want solc duration only, got %+v", out.Timing)
+	}
+
+	// cached outputs have no solc duration
+	out, err = c.CompileOutput(srcDir, nil)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if out.Timing.SolcDuration != 0 {
+		t.Fatalf("want no solc duration, got %+v", out.Timing)
+	}
+
+	// the download is only reported with the first output
+	c = newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}}`)
+	c.downloadDuration = time.Second
+	for i, want := range []time.Duration{time.Second, 0} {
+		out, err := c.CompileOutput(srcDir, nil)
+		if err != nil {
+			t.Fatalf("%d: Compile failed: %v", i, err)
+		}
+		if out.Timing.DownloadDuration != want {
+			t.Fatalf("%d: want download duration %v, got %+v", i, want, out.Timing)
+		}
+	}
+}
+
+func TestCompileWithMeta(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}}`)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	for i, wantFromCache := range []bool{false, true} {
+		contracts, meta, err := c.CompileWithMeta(srcDir, "Test", nil)
+		if err != nil {
+			t.Fatalf("%d: Compile failed: %v", i, err)
+		}
+		if _, ok := contracts["Test.sol"]["Test"]; !ok {
+			t.Fatalf("%d: want contract Test, got %v", i, contracts)
+		}
+		if meta.FromCache != wantFromCache {
+			t.Fatalf("%d: want FromCache %t, got %+v", i, wantFromCache, meta)
+		}
+	}
+
+	// outputs compiled without cache are fresh
+	_, meta, err := c.CompileWithMeta(srcDir, "Test", nil, func(s *Settings) { s.noCache = true })
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if meta.FromCache || meta.Timing.SolcDuration <= 0 {
+		t.Fatalf("want fresh output, got %+v", meta)
+	}
+}
+
+func TestCompileContractNotFound(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}}`)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	_, err := c.Compile(srcDir, "Typo", nil)
+	if want := `solc: contract "Typo" not found`; err == nil || err.Error() != want {
+		t.Fatalf("want error %q, got %v", want, err)
+	}
+}
+
+func TestCompileNodeModules(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}}`)
+	root := t.TempDir()
+	srcDir := filepath.Join(root, "src")
+	pkgDir := filepath.Join(root, "node_modules", "@lib", "pkg")
+	for _, dir := range []string{srcDir, pkgDir} {
+		if err := os.MkdirAll(dir, 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	createDummyContract(t, pkgDir, "Lib", "")
+	createDummyContract(t, srcDir, "Test", `import "@lib/pkg/Lib.sol";`)
+
+	if _, err := c.Compile(srcDir, "Test", nil, WithRemappings([]string{"x/=y/"})); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	got := fakeInput(t, c)["settings"].(map[string]any)["remappings"]
+	want := []any{"x/=y/", "@lib/pkg/=../node_modules/@lib/pkg/"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+
+	// the built input matches the input passed to solc
+	data, err := c.BuildInput(srcDir, nil, WithRemappings([]string{"x/=y/"}))
+	if err != nil {
+		t.Fatalf("Failed to build input: %v", err)
+	}
+	var input map[string]any
+	if err := json.Unmarshal(data, &input); err != nil {
+		t.Fatalf("Failed to decode input: %v", err)
+	}
+	if diff := cmp.Diff(fakeInput(t, c), input); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+
+	// edits of package files invalidate the cache
+	createDummyContract(t, pkgDir, "Lib", "library Lib {}")
+	misses := c.CacheStats().Misses
+	if _, err := c.Compile(srcDir, "Test", nil, WithRemappings([]string{"x/=y/"})); err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if got := c.CacheStats().Misses - misses; got != 1 {
+		t.Fatalf("want 1 cache miss after package edit, got %d", got)
+	}
+}
+
+func TestCompileReturnSources(t *testing.T) {
+	srcDir, libDir := t.TempDir(), t.TempDir()
+	createDummyContract(t, srcDir, "Test", `import "lib/Lib.sol";`)
+	createDummyContract(t, libDir, "Lib", "library Lib {}")
+	libPath := filepath.ToSlash(filepath.Join(libDir, "Lib.sol"))
+
+	c := newFakeCompiler(t, `{"sources": {"Test.sol": {"id": 0}, "`+libPath+`": {"id": 1}}}`)
+	out, err := c.CompileOutput(srcDir, nil, WithReturnSources(), WithRemappings([]string{"lib/=" + libDir + "/"}))
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	want := map[string]string{
+		"Test.sol": `import "lib/Lib.sol";`,
+		libPath:    "library Lib {}",
+	}
+	got := make(map[string]string)
+	for name, source := range out.Sources {
+		got[name] = source.Content
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+
+	// the returned sources are passed to solc by content
+	source := fakeInput(t, c)["sources"].(map[string]any)["Test.sol"].(map[string]any)
+	if source["content"] != want["Test.sol"] {
+		t.Fatalf("want source passed by content, got %v", source)
+	}
+
+	// sources are only returned if requested
+	out, err = c.CompileOutput(srcDir, nil)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if content := out.Sources["Test.sol"].Content; content != "" {
+		t.Fatalf("want no source content, got %q", content)
+	}
+
+	// sources of unknown content are reported
+	c = newFakeCompiler(t, `{"sources": {"Test.sol": {"id": 0}, "Missing.sol": {"id": 1}}}`)
+	_, err = c.CompileOutput(srcDir, nil, WithReturnSources(), WithRemappings([]string{"lib/=" + libDir + "/"}))
+	if want := `solc: content of source "Missing.sol" not found`; err == nil || err.Error() != want {
+		t.Fatalf("want error %q, got %v", want, err)
+	}
+}
+
+func TestCompileBatch(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"A.sol": {"A": {}, "B": {}}, "lib/C.sol": {"C": {}}}}`)
+
+	contracts, err := c.CompileBatch(map[string]string{
+		"A.sol":     `import "./lib/C.sol";`,
+		"lib/C.sol": "pragma solidity ^0.8.0;",
+	}, nil)
+	if err != nil {
+		t.Fatalf("CompileBatch failed: %v", err)
+	}
+	want := []string{"A.sol:A", "A.sol:B", "lib/C.sol:C"}
+	if diff := cmp.Diff(want, slices.Sorted(maps.Keys(contracts))); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+
+	sources := fakeInput(t, c)["sources"].(map[string]any)
+	if len(sources) != 3 { // including console.sol
+		t.Fatalf("want all sources in a single input, got %v", sources)
+	}
+}
+
+func TestCompileFS(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"A.sol": {"A": {}}, "lib/C.sol": {"C": {}}}}`)
+
+	fsys := fstest.MapFS{
+		"contracts/A.sol":     {Data: []byte(`import "./lib/C.sol";`)},
+		"contracts/lib/C.sol": {Data: []byte("pragma solidity ^0.8.0;")},
+		"contracts/README.md": {Data: []byte("not a source")},
+	}
+	contracts, err := c.CompileFS(fsys, "contracts", "", nil)
+	if err != nil {
+		t.Fatalf("CompileFS failed: %v", err)
+	}
+	if len(contracts) != 2 {
+		t.Fatalf("want 2 source files, got %v", contracts)
+	}
+
+	sources := fakeInput(t, c)["sources"].(map[string]any)
+	want := map[string]any{"content": "pragma solidity ^0.8.0;"}
+	if diff := cmp.Diff(want, sources["lib/C.sol"]); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+
+	if _, err := c.CompileFS(fsys, "missing", "", nil); err == nil {
+		t.Fatal("want error for missing root")
+	}
+}
+
+func TestCompileErrorRecovery(t *testing.T) {
+	error_ := `{"severity": "error", "type": "ParserError", "formattedMessage": "ParserError: Expected ';'."}`
+	c := newFakeCompiler(t, `{"errors": [`+error_+`, `+error_+`], "contracts": {"Test.sol": {"Test": {}}}}`)
+	c.version = maxErrorRecoveryVersion
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "contract Test { uint x }")
+
+	contracts, diags, err := c.CompileWithDiagnostics(srcDir, "Test", nil, WithErrorRecovery())
+	if err == nil {
+		t.Fatal("want compilation error")
+	}
+	if len(diags) != 2 || len(contracts["Test.sol"]) != 1 {
+		t.Fatalf("want partial output with 2 diagnostics, got %v, %v", contracts, diags)
+	}
+	if got := fakeInput(t, c)["settings"].(map[string]any)["parserErrorRecovery"]; got != true {
+		t.Fatalf("want parserErrorRecovery, got %v", got)
+	}
+
+	// without error recovery, no contracts are returned
+	if contracts, _, err := c.CompileWithDiagnostics(srcDir, "Test", nil); err == nil || contracts != nil {
+		t.Fatalf("want only error, got %v, %v", contracts, err)
+	}
+}
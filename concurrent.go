@@ -0,0 +1,59 @@
+package solc
+
+import (
+	"errors"
+	"fmt"
+	"runtime"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// CompileJob is a compilation of [Compiler.CompileMany].
+type CompileJob struct {
+	Dir             string                         // Directory to compile.
+	Contract        string                         // Contract name, or empty for all contracts.
+	OutputSelection map[string]map[string][]string // Output selection, or nil for the default.
+	Options         []Option                       // Compilation options.
+}
+
+// CompileResult is the result of a [CompileJob].
+type CompileResult struct {
+	Contracts map[string]map[string]Contract // Keyed by source file and contract name.
+	Err       error                          // Error of the compilation, if any.
+}
+
+// CompileMany runs the given compilations concurrently, using at most the
+// number of solc processes configured by [WithConcurrency]. The results are
+// returned in the order of the jobs. If any compilation failed, all results
+// are returned together with an error joining the errors of the failed jobs.
+func (c *Compiler) CompileMany(jobs []CompileJob) ([]CompileResult, error) {
+	results := make([]CompileResult, len(jobs))
+
+	var g errgroup.Group
+	g.SetLimit(c.maxConcurrency())
+	for i, job := range jobs {
+		g.Go(func() error {
+			contracts, err := c.Compile(job.Dir, job.Contract, job.OutputSelection, job.Options...)
+			results[i] = CompileResult{Contracts: contracts, Err: err}
+			return nil
+		})
+	}
+	g.Wait()
+
+	var errs []error
+	for i, result := range results {
+		if result.Err != nil {
+			errs = append(errs, fmt.Errorf("job %d (%s): %w", i, jobs[i].Dir, result.Err))
+		}
+	}
+	return results, errors.Join(errs...)
+}
+
+// maxConcurrency returns the maximum number of concurrent solc processes of
+// [Compiler.CompileMany].
+func (c *Compiler) maxConcurrency() int {
+	if c.concurrency > 0 {
+		return c.concurrency
+	}
+	return runtime.NumCPU()
+}
@@ -0,0 +1,36 @@
+package solc
+
+import (
+	"path/filepath"
+	"strconv"
+	"testing"
+)
+
+func TestCompileMany(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}}`)
+	WithConcurrency(2)(c)
+
+	var jobs []CompileJob
+	for i := 0; i < 4; i++ {
+		srcDir := t.TempDir()
+		createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8."+strconv.Itoa(i)+";")
+		jobs = append(jobs, CompileJob{Dir: srcDir, Contract: "Test"})
+	}
+	jobs = append(jobs, CompileJob{Dir: filepath.Join(t.TempDir(), "missing")})
+
+	results, err := c.CompileMany(jobs)
+	if err == nil {
+		t.Fatal("want error of the missing directory")
+	}
+	if len(results) != len(jobs) {
+		t.Fatalf("want %d results, got %d", len(jobs), len(results))
+	}
+	for i, result := range results[:4] {
+		if result.Err != nil || len(result.Contracts["Test.sol"]) != 1 {
+			t.Fatalf("job %d: want contract Test, got %v, %v", i, result.Contracts, result.Err)
+		}
+	}
+	if results[4].Err == nil {
+		t.Fatal("want error of job 4")
+	}
+}
@@ -0,0 +1,30 @@
+package solc
+
+import "fmt"
+
+// DeployData returns the calldata of a contract creation transaction deploying
+// the given compiled contract: its creation bytecode followed by the given
+// constructor arguments, ABI encoded using the ABI of the contract.
+//
+// The contract must have been compiled with the ABI and bytecode selected and
+// its libraries linked, see [Bytecode.Link]. Arguments are given as Go values
+// as accepted by go-ethereum's abi.ABI.Pack, e.g. *big.Int for uint256.
+func DeployData(result Contract, args ...any) ([]byte, error) {
+	code, err := result.EVM.Bytecode.Bytes()
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == 0 {
+		return nil, fmt.Errorf("solc: contract has no bytecode")
+	}
+
+	parsed, err := result.ParsedABI()
+	if err != nil {
+		return nil, err
+	}
+	encodedArgs, err := parsed.Pack("", args...)
+	if err != nil {
+		return nil, fmt.Errorf("solc: failed to encode constructor arguments: %w", err)
+	}
+	return append(code, encodedArgs...), nil
+}
@@ -0,0 +1,56 @@
+package solc
+
+import (
+	"encoding/json"
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDeployData(t *testing.T) {
+	constructor := json.RawMessage(`{"type":"constructor","inputs":[{"name":"x","type":"uint256"},{"name":"a","type":"address"}],"stateMutability":"nonpayable"}`)
+	withArgs := Contract{
+		ABI: []json.RawMessage{constructor},
+		EVM: EVM{Bytecode: Bytecode{Object: hexBytes{0x60, 0x80}}},
+	}
+	noArgs := Contract{EVM: EVM{Bytecode: Bytecode{Object: hexBytes{0x60, 0x80}}}}
+
+	tests := []struct {
+		Contract Contract
+		Args     []any
+		Want     string
+		WantErr  string
+	}{
+		{
+			Contract: withArgs,
+			Args:     []any{big.NewInt(1), common.HexToAddress("0x00000000000000000000000000000000000000aa")},
+			Want:     "6080" + strings.Repeat("0", 63) + "1" + strings.Repeat("0", 62) + "aa",
+		},
+		{Contract: noArgs, Want: "6080"},
+		{Contract: withArgs, Args: []any{big.NewInt(1)}, WantErr: "failed to encode constructor arguments"},
+		{Contract: noArgs, Args: []any{big.NewInt(1)}, WantErr: "failed to encode constructor arguments"},
+		{Contract: Contract{EVM: EVM{Bytecode: Bytecode{Object: make(hexBytes, 21), LinkReferences: map[string]map[string][]LinkRef{"L.sol": {"L": {{Start: 1, Length: 20}}}}}}}, WantErr: "unlinked libraries: L.sol:L"},
+		{Contract: Contract{}, WantErr: "no bytecode"},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := DeployData(test.Contract, test.Args...)
+			if test.WantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), test.WantErr) {
+					t.Fatalf("want error containing %q, got %v", test.WantErr, err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("DeployData failed: %v", err)
+			}
+			if want := mustDecodeHex(t, test.Want); string(got) != string(want) {
+				t.Fatalf("want %x, got %x", want, got)
+			}
+		})
+	}
+}
@@ -2,13 +2,21 @@ package solc
 
 import (
 	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
 	"os"
 	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+	"time"
 
+	"github.com/raszia/go-solc/internal/version"
 	"golang.org/x/sync/singleflight"
 )
 
@@ -16,95 +24,412 @@ var (
 	MaxRetryDownloadAttempts = 2
 
 	dg singleflight.Group // global download group
+
+	// defaultHTTPClient is the HTTP client used if none is set using
+	// [WithHTTPClient]. The timeout covers the complete download of a solc
+	// binary.
+	defaultHTTPClient = &http.Client{Timeout: 10 * time.Minute}
+
+	// global cache of version lists, keyed by URL
+	versionListMux   sync.Mutex
+	versionListCache = make(map[string][]Version)
 )
 
 // checkSolc checks for the existence of the solc binary
 // and attempts to download it if it does
-// not exist yet.
+// not exist yet, unless the compiler is in offline mode.
 //
 // The version string must be in the format "0.8.17".
-func checkSolc(version Version, binPath string) (string, error) {
+func (c *Compiler) checkSolc() (string, error) {
+	version, binPath := c.version, c.binPath
+
+	pinned, hasPin, err := c.expectedChecksum()
+	if err != nil {
+		return "", err
+	}
+
+	// without native binaries, soljson.js is used in online and offline mode
+	platformErr := checkPlatform()
+	if platformErr != nil && c.solJSRunner != nil {
+		return c.checkSolJS(pinned, hasPin)
+	}
+
+	absSolcPath := filepath.Join(binPath, solcBinaryName(version))
+	if c.offline {
+		if !fileExists(absSolcPath) {
+			return "", fmt.Errorf("solc: version %q is not installed in %s (offline mode)", version, binPath)
+		}
+		if hasPin {
+			if err := verifyInstalledChecksum(version, absSolcPath, pinned); err != nil {
+				return "", err
+			}
+		}
+		return absSolcPath, nil
+	}
+
+	if platformErr != nil {
+		return "", platformErr
+	}
 	v, ok := solcVersions[version]
 	if !ok {
 		return "", fmt.Errorf("solc: unknown version %q", version)
 	}
+	if hasPin {
+		// only accept downloads matching the pinned checksum
+		v.Sha256 = pinned
+	}
 
 	if err := makeBinDir(binPath); err != nil {
 		return "", err
 	}
 
-	absSolcPath := filepath.Join(binPath, fmt.Sprintf("solc_v%s", version))
 	if fileExists(absSolcPath) {
+		if hasPin {
+			if err := verifyInstalledChecksum(version, absSolcPath, pinned); err != nil {
+				return "", err
+			}
+		}
 		return absSolcPath, nil
 	}
-	_, err, _ := dg.Do(version.String(), func() (any, error) {
-		if _, err := os.Stat(absSolcPath); errors.Is(err, os.ErrNotExist) {
-			// download solc_{version}
-			var (
-				try int
-				err error
-			)
-			for ; try < MaxRetryDownloadAttempts && (try == 0 || err != nil); try++ {
-				err = downloadSolc(absSolcPath, v)
-			}
-			if try >= MaxRetryDownloadAttempts {
-				return "", fmt.Errorf("solc: failed to download solc %q: %w", version, err)
-			}
+	// concurrent downloads of the same binary are deduplicated. Across
+	// processes, the binary is written to a temporary file first and renamed
+	// once it is complete, so a binary at absSolcPath is always complete.
+	start := time.Now()
+	_, err, _ = dg.Do(absSolcPath, func() (any, error) {
+		if fileExists(absSolcPath) {
+			return nil, nil
 		}
 
-		// solc_{version} binary exists
-		f, err := os.Open(absSolcPath)
+		// download solc_{version}
+		baseURL, err := c.downloadBaseURL()
 		if err != nil {
-			return "", err
+			return nil, err
 		}
-		defer f.Close()
-
-		return nil, verifyChecksum(version, f, v)
+		url := baseURL + v.Path
+		err = c.withRetries(url, func(attempt int) error {
+			c.log().Info("downloading solc", "version", version, "url", url, "attempt", attempt)
+			start := time.Now()
+			if err := c.downloadSolc(absSolcPath, v); err != nil {
+				c.log().Warn("failed to download solc", "version", version, "err", err)
+				return err
+			}
+			c.log().Info("downloaded solc", "version", version, "path", absSolcPath, "duration", time.Since(start))
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("solc: failed to download solc %q: %w", version, err)
+		}
+		return nil, nil
 	})
 
 	if err != nil {
 		return "", err
 	}
+	c.downloadDuration = time.Since(start)
 	return absSolcPath, nil
 }
 
-func verifyChecksum(version Version, r io.Reader, v solcVersion) error {
-	hash := sha256.New()
-	if _, err := io.Copy(hash, r); err != nil {
+// expectedChecksum returns the checksum of the solc binary of the compiler's
+// version pinned by [WithExpectedChecksum], or false if none is pinned.
+func (c *Compiler) expectedChecksum() ([32]byte, bool, error) {
+	var sum [32]byte
+	pin, ok := c.checksums[c.version]
+	if !ok {
+		return sum, false, nil
+	}
+	b, err := hex.DecodeString(strings.TrimPrefix(pin, "0x"))
+	if err != nil || len(b) != len(sum) {
+		return sum, false, fmt.Errorf("solc: invalid expected checksum %q for version %q", pin, c.version)
+	}
+	copy(sum[:], b)
+	return sum, true, nil
+}
+
+// verifyInstalledChecksum checks that the SHA256 checksum of the installed solc
+// binary at the given path matches the expected checksum.
+func verifyInstalledChecksum(version Version, path string, wantSha256 [32]byte) error {
+	f, err := os.Open(path)
+	if err != nil {
 		return err
 	}
+	defer f.Close()
 
+	hash := sha256.New()
+	if _, err := io.Copy(hash, f); err != nil {
+		return err
+	}
 	var gotSha256 [32]byte
 	hash.Sum(gotSha256[:0])
+	if gotSha256 != wantSha256 {
+		return fmt.Errorf("solc: checksum mismatch for installed version %q: want %x, got %x", version, wantSha256, gotSha256)
+	}
+	return nil
+}
 
+// verifyChecksum checks that the given SHA256 checksum matches the published
+// checksum of the solc binary.
+func verifyChecksum(version Version, gotSha256 [32]byte, v solcVersion) error {
 	if v.Sha256 != gotSha256 {
-		return fmt.Errorf("solc: checksum mismatch for version %q", version)
+		return fmt.Errorf("solc: checksum mismatch for version %q: want %x, got %x", version, v.Sha256, gotSha256)
 	}
 	return nil
 }
 
-// downloadSolc downloads the solc binary with the given version, writes it to a
-// file at the given path and return its content.
-func downloadSolc(path string, v solcVersion) error {
+// downloadSolc downloads the solc binary of the compilers version and writes it
+// to a file at the given path. The file is only created if the checksum of the
+// downloaded binary matches the published checksum.
+func (c *Compiler) downloadSolc(path string, v solcVersion) error {
+	baseURL, err := c.downloadBaseURL()
+	if err != nil {
+		return err
+	}
+	return c.download(baseURL, path, v)
+}
+
+// download downloads the file at the given base URL and the path of the given
+// version and writes it to a file at the given path. The file is only created
+// if the checksum of the downloaded file matches the checksum of the version.
+func (c *Compiler) download(baseURL, path string, v solcVersion) error {
 	// request compiler
-	resp, err := http.Get(solcBaseURL + v.Path)
+	resp, err := c.httpClient().Get(baseURL + v.Path)
 	if err != nil {
 		return err
 	}
 	defer resp.Body.Close()
 
-	// create file
-	f, err := os.OpenFile(path, os.O_WRONLY|os.O_CREATE, 0o0764)
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("solc: failed to download %q: %w", v.Path, newStatusError(resp))
+	}
+
+	// create temporary file, which is removed unless the download succeeds
+	f, err := os.CreateTemp(filepath.Dir(path), filepath.Base(path)+".*.tmp")
 	if err != nil {
 		return err
 	}
+	defer os.Remove(f.Name())
 	defer f.Close()
 
-	// copy response body to file
-	if _, err := io.Copy(f, resp.Body); err != nil {
+	// copy response body to file and hash it
+	hash := sha256.New()
+	w := io.MultiWriter(f, hash)
+	if c.progress != nil {
+		w = io.MultiWriter(w, &progressWriter{total: resp.ContentLength, fn: c.progress})
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
 		return err
 	}
-	return nil
+
+	var gotSha256 [32]byte
+	hash.Sum(gotSha256[:0])
+	if err := verifyChecksum(c.version, gotSha256, v); err != nil {
+		return err
+	}
+
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(f.Name(), 0o0764); err != nil {
+		return err
+	}
+	return os.Rename(f.Name(), path)
+}
+
+// progressWriter reports the number of bytes written to it.
+type progressWriter struct {
+	written int64
+	total   int64
+	fn      func(downloaded, total int64)
+}
+
+func (w *progressWriter) Write(p []byte) (int, error) {
+	w.written += int64(len(p))
+	w.fn(w.written, w.total)
+	return len(p), nil
+}
+
+// AvailableVersions returns all solc versions that are available for download
+// for the current platform in ascending order. The version list is fetched once
+// and cached for the lifetime of the process.
+//
+// The download related [CompilerOption]s such as [WithDownloadBaseURL] and
+// [WithOffline] are respected.
+func AvailableVersions(opts ...CompilerOption) ([]Version, error) {
+	c := new(Compiler)
+	for _, opt := range opts {
+		opt(c)
+	}
+
+	if c.offline {
+		return nil, fmt.Errorf("solc: failed to fetch version list: offline mode")
+	}
+
+	baseURL, err := c.downloadBaseURL()
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []Version
+	url := baseURL + "list.json"
+	err = c.withRetries(url, func(int) (err error) {
+		versions, err = fetchVersionList(c.httpClient(), url)
+		return err
+	})
+	return versions, err
+}
+
+// downloadBaseURL returns the base URL to download the version list and solc
+// binaries from, i.e. the solc-bin directory of the current platform unless
+// [WithDownloadBaseURL] is set.
+func (c *Compiler) downloadBaseURL() (string, error) {
+	if c.baseURL != "" {
+		return c.baseURL, nil
+	}
+	platform, err := solcPlatform(goos, goarch)
+	if err != nil {
+		return "", err
+	}
+	return solcBinURL + platform + "/", nil
+}
+
+// httpClient returns the HTTP client to download the version list and solc
+// binaries with.
+func (c *Compiler) httpClient() *http.Client {
+	if c.client == nil {
+		return defaultHTTPClient
+	}
+	return c.client
+}
+
+func fetchVersionList(client *http.Client, url string) ([]Version, error) {
+	versionListMux.Lock()
+	defer versionListMux.Unlock()
+
+	if versions, ok := versionListCache[url]; ok {
+		return slices.Clone(versions), nil
+	}
+
+	resp, err := client.Get(url)
+	if err != nil {
+		return nil, fmt.Errorf("solc: failed to fetch version list: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("solc: failed to fetch version list: %w", newStatusError(resp))
+	}
+
+	var list struct {
+		Builds []struct {
+			Version    string `json:"version"`
+			Prerelease string `json:"prerelease"`
+		} `json:"builds"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return nil, fmt.Errorf("solc: failed to decode version list: %w", err)
+	}
+
+	var versions []Version
+	for _, build := range list.Builds {
+		if build.Prerelease != "" || !version.IsValid(build.Version) {
+			continue
+		}
+		versions = append(versions, Version(build.Version))
+	}
+	slices.SortFunc(versions, Version.Cmp)
+	versions = slices.Compact(versions)
+
+	versionListCache[url] = versions
+	return slices.Clone(versions), nil
+}
+
+// Install installs the solc binary of the given version or version constraint
+// in the given bin directory without compiling anything, e.g. to provision solc
+// in a setup step. The binary is downloaded and its checksum verified unless
+// it is installed already. The resolved version is returned.
+func Install(version Version, binPath string, opts ...CompilerOption) (Version, error) {
+	c, err := New(version, binPath, opts...)
+	if err != nil {
+		return "", err
+	}
+	return c.Version(), nil
+}
+
+// ListInstalled returns the solc versions that are installed in the given bin
+// directory in ascending order. Files that are not solc binaries are ignored.
+func ListInstalled(binPath string) ([]Version, error) {
+	entries, err := os.ReadDir(binPath)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	} else if err != nil {
+		return nil, err
+	}
+
+	var versions []Version
+	for _, entry := range entries {
+		v, ok := parseSolcBinaryName(entry.Name())
+		if !ok || !entry.Type().IsRegular() || !version.IsValid(string(v)) {
+			continue
+		}
+		versions = append(versions, v)
+	}
+	slices.SortFunc(versions, Version.Cmp)
+	return versions, nil
+}
+
+// PruneResult is the result of [Prune] and [PruneOlderThan].
+type PruneResult struct {
+	Removed []Version // Removed versions in ascending order.
+	Bytes   int64     // Number of bytes reclaimed.
+}
+
+// Prune removes all but the given number of most recent solc versions installed
+// in the given bin directory.
+func Prune(binPath string, keep int) (PruneResult, error) {
+	versions, err := ListInstalled(binPath)
+	if err != nil {
+		return PruneResult{}, err
+	}
+	if keep < 0 {
+		keep = 0
+	}
+	if len(versions) <= keep {
+		return PruneResult{}, nil
+	}
+	return removeVersions(binPath, versions[:len(versions)-keep])
+}
+
+// PruneOlderThan removes all solc versions older than the given version that
+// are installed in the given bin directory.
+func PruneOlderThan(binPath string, version Version) (PruneResult, error) {
+	versions, err := ListInstalled(binPath)
+	if err != nil {
+		return PruneResult{}, err
+	}
+	versions = slices.DeleteFunc(versions, func(v Version) bool { return v.Cmp(version) >= 0 })
+	return removeVersions(binPath, versions)
+}
+
+// removeVersions removes the solc binaries of the given versions, including
+// soljson.js builds, from the given bin directory.
+func removeVersions(binPath string, versions []Version) (PruneResult, error) {
+	var res PruneResult
+	for _, v := range versions {
+		for _, name := range []string{solcBinaryName(v), soljsonName(v)} {
+			path := filepath.Join(binPath, name)
+			stat, err := os.Stat(path)
+			if errors.Is(err, os.ErrNotExist) {
+				continue
+			} else if err != nil {
+				return res, err
+			}
+			if err := os.Remove(path); err != nil {
+				return res, err
+			}
+			res.Bytes += stat.Size()
+		}
+		res.Removed = append(res.Removed, v)
+	}
+	return res, nil
 }
 
 // makeBinDir creates the directory ".solc/bin/" if it doesn't exist yet.
@@ -125,3 +450,56 @@ func fileExists(path string) bool {
 	}
 	return false
 }
+
+// retryPolicy is the retry policy of downloads, see [WithDownloadRetries].
+type retryPolicy struct {
+	retries   int           // Maximum number of retries
+	baseDelay time.Duration // Delay before the first retry, doubled for each further retry
+}
+
+// withRetries calls the given download function of the given URL with the
+// number of the attempt, starting at 1, and retries it according to the retry
+// policy of the compiler as long as it fails with a transient error.
+func (c *Compiler) withRetries(url string, download func(attempt int) error) error {
+	retries, delay := MaxRetryDownloadAttempts-1, time.Duration(0)
+	if c.retry != nil {
+		retries, delay = c.retry.retries, c.retry.baseDelay
+	}
+
+	for try := 0; ; try++ {
+		err := download(try + 1)
+		if err == nil || try >= retries || !isTransient(err) {
+			return err
+		}
+		c.log().Debug("retrying download", "url", url, "delay", delay)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// statusError is the error of an HTTP response with an unexpected status.
+type statusError struct {
+	code   int
+	status string
+}
+
+func newStatusError(resp *http.Response) *statusError {
+	return &statusError{code: resp.StatusCode, status: resp.Status}
+}
+
+func (e *statusError) Error() string { return e.status }
+
+// isTransient reports whether the given download error may not occur again
+// when retrying the download: network errors, truncated responses and HTTP
+// responses with status 5xx, 408 or 429. Other errors, e.g. a 404 of an
+// unknown version or a checksum mismatch, are permanent.
+func isTransient(err error) bool {
+	var statusErr *statusError
+	if errors.As(err, &statusErr) {
+		return statusErr.code >= 500 ||
+			statusErr.code == http.StatusRequestTimeout ||
+			statusErr.code == http.StatusTooManyRequests
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr) || errors.Is(err, io.ErrUnexpectedEOF)
+}
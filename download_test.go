@@ -1,15 +1,32 @@
 package solc
 
-import "testing"
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/google/go-cmp/cmp"
+)
 
 func TestDownloadSolc(t *testing.T) {
 	errCh := make(chan error, 2)
 	go func() {
-		_, err := checkSolc("0.8.21", "./.solc")
+		c := &Compiler{version: "0.8.21", binPath: "./.solc"}
+		_, err := c.checkSolc()
 		errCh <- err
 	}()
 
-	_, err := checkSolc("0.8.21", "./.solc")
+	c := &Compiler{version: "0.8.21", binPath: "./.solc"}
+	_, err := c.checkSolc()
 	errCh <- err
 
 	for i := 0; i < cap(errCh); i++ {
@@ -18,3 +35,428 @@ func TestDownloadSolc(t *testing.T) {
 		}
 	}
 }
+
+func TestListInstalled(t *testing.T) {
+	binPath := t.TempDir()
+	for _, name := range []string{"solc_v0.8.30", "solc_v0.8.9", "solc_v0.5.0", "solc_vfoo", "solc", "README.md"} {
+		if err := os.WriteFile(filepath.Join(binPath, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := os.Mkdir(filepath.Join(binPath, "solc_v0.8.1"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+
+	got, err := ListInstalled(binPath)
+	if err != nil {
+		t.Fatalf("Failed to list installed versions: %v", err)
+	}
+	want := []Version{"0.5.0", "0.8.9", "0.8.30"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
+
+func TestListInstalledMissingDir(t *testing.T) {
+	got, err := ListInstalled(filepath.Join(t.TempDir(), "missing"))
+	if err != nil || len(got) != 0 {
+		t.Fatalf("want no versions and no error, got %v, %v", got, err)
+	}
+}
+
+func TestAvailableVersions(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if r.URL.Path != "/list.json" {
+			http.NotFound(w, r)
+			return
+		}
+		w.Write([]byte(`{"builds": [
+			{"version": "0.8.9"},
+			{"version": "0.8.30"},
+			{"version": "0.5.0"},
+			{"version": "0.8.31", "prerelease": "nightly.2025.6.1"}
+		]}`))
+	}))
+	defer srv.Close()
+
+	for i := 0; i < 2; i++ {
+		got, err := AvailableVersions(WithDownloadBaseURL(srv.URL))
+		if err != nil {
+			t.Fatalf("Failed to fetch available versions: %v", err)
+		}
+		want := []Version{"0.5.0", "0.8.9", "0.8.30"}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("(-want +got)\n%s", diff)
+		}
+	}
+	if requests != 1 {
+		t.Fatalf("want 1 request, got %d", requests)
+	}
+}
+
+func TestAvailableVersionsOffline(t *testing.T) {
+	srv := httptest.NewServer(http.NotFoundHandler())
+	srv.Close()
+
+	if _, err := AvailableVersions(WithDownloadBaseURL(srv.URL)); err == nil {
+		t.Fatal("want error")
+	}
+	if _, err := AvailableVersions(WithOffline()); err == nil {
+		t.Fatal("want error in offline mode")
+	}
+}
+
+func TestDownloadSolcChecksum(t *testing.T) {
+	bin := []byte("#!/bin/sh\necho solc\n")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bin)
+	}))
+	defer srv.Close()
+
+	t.Run("match", func(t *testing.T) {
+		path := filepath.Join(t.TempDir(), "solc_v0.8.30")
+		c := &Compiler{version: "0.8.30", baseURL: srv.URL + "/"}
+		if err := c.downloadSolc(path, solcVersion{Path: "solc", Sha256: sha256.Sum256(bin)}); err != nil {
+			t.Fatalf("Failed to download solc: %v", err)
+		}
+		got, err := os.ReadFile(path)
+		if err != nil {
+			t.Fatalf("Failed to read solc: %v", err)
+		}
+		if !bytes.Equal(bin, got) {
+			t.Fatalf("want %q, got %q", bin, got)
+		}
+	})
+
+	t.Run("mismatch", func(t *testing.T) {
+		dir := t.TempDir()
+		path := filepath.Join(dir, "solc_v0.8.30")
+		c := &Compiler{version: "0.8.30", baseURL: srv.URL + "/"}
+		err := c.downloadSolc(path, solcVersion{Path: "solc", Sha256: [32]byte{0xc0, 0xfe}})
+		if err == nil || !strings.Contains(err.Error(), "c0fe") || !strings.Contains(err.Error(), fmt.Sprintf("%x", sha256.Sum256(bin))) {
+			t.Fatalf("want checksum mismatch error naming both checksums, got %v", err)
+		}
+
+		// the partial download is removed
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(entries) != 0 {
+			t.Fatalf("want empty directory, got %v", entries)
+		}
+	})
+}
+
+func TestCheckSolcConcurrent(t *testing.T) {
+	bin := []byte("#!/bin/sh\necho solc\n")
+
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		time.Sleep(50 * time.Millisecond) // keep the download in flight
+		w.Write(bin)
+	}))
+	defer srv.Close()
+
+	const version Version = "0.0.1"
+	solcVersions[version] = solcVersion{Path: "solc", Sha256: sha256.Sum256(bin)}
+	defer delete(solcVersions, version)
+
+	binPath := t.TempDir()
+	const n = 8
+	errCh := make(chan error, n)
+	for i := 0; i < n; i++ {
+		go func() {
+			c := &Compiler{version: version, binPath: binPath, baseURL: srv.URL + "/"}
+			_, err := c.checkSolc()
+			errCh <- err
+		}()
+	}
+	for i := 0; i < n; i++ {
+		if err := <-errCh; err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("want 1 download, got %d", got)
+	}
+	got, err := os.ReadFile(filepath.Join(binPath, "solc_v"+version.String()))
+	if err != nil {
+		t.Fatalf("Failed to read solc: %v", err)
+	}
+	if !bytes.Equal(bin, got) {
+		t.Fatalf("want %q, got %q", bin, got)
+	}
+}
+
+func TestDownloadSolcProgress(t *testing.T) {
+	bin := []byte("#!/bin/sh\necho solc\n")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/chunked" {
+			// flush before writing the body, such that the content length is
+			// unknown
+			w.(http.Flusher).Flush()
+		}
+		w.Write(bin)
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		Path      string
+		WantTotal int64
+	}{
+		{Path: "solc", WantTotal: int64(len(bin))},
+		{Path: "chunked", WantTotal: -1},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Path, func(t *testing.T) {
+			var downloaded, total int64
+			c := &Compiler{version: "0.8.30", baseURL: srv.URL + "/"}
+			WithDownloadProgress(func(d, n int64) { downloaded, total = d, n })(c)
+
+			path := filepath.Join(t.TempDir(), "solc_v0.8.30")
+			if err := c.downloadSolc(path, solcVersion{Path: test.Path, Sha256: sha256.Sum256(bin)}); err != nil {
+				t.Fatalf("Failed to download solc: %v", err)
+			}
+			if downloaded != int64(len(bin)) || total != test.WantTotal {
+				t.Fatalf("want progress %d/%d, got %d/%d", len(bin), test.WantTotal, downloaded, total)
+			}
+		})
+	}
+}
+
+// roundTripFunc is an [http.RoundTripper] implemented by a function.
+type roundTripFunc func(*http.Request) (*http.Response, error)
+
+func (fn roundTripFunc) RoundTrip(req *http.Request) (*http.Response, error) { return fn(req) }
+
+func TestWithHTTPClient(t *testing.T) {
+	bin := []byte("#!/bin/sh\necho solc\n")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if strings.HasSuffix(r.URL.Path, "/list.json") {
+			w.Write([]byte(`{"builds": [{"version": "0.8.30"}]}`))
+			return
+		}
+		w.Write(bin)
+	}))
+	defer srv.Close()
+
+	var paths []string
+	client := &http.Client{Transport: roundTripFunc(func(req *http.Request) (*http.Response, error) {
+		paths = append(paths, req.URL.Path)
+		return http.DefaultTransport.RoundTrip(req)
+	})}
+	opts := []CompilerOption{WithHTTPClient(client), WithDownloadBaseURL(srv.URL + "/client/")}
+
+	if _, err := AvailableVersions(opts...); err != nil {
+		t.Fatalf("Failed to fetch available versions: %v", err)
+	}
+
+	c := &Compiler{version: "0.8.30"}
+	for _, opt := range opts {
+		opt(c)
+	}
+	path := filepath.Join(t.TempDir(), "solc_v0.8.30")
+	if err := c.downloadSolc(path, solcVersion{Path: "solc", Sha256: sha256.Sum256(bin)}); err != nil {
+		t.Fatalf("Failed to download solc: %v", err)
+	}
+
+	want := []string{"/client/list.json", "/client/solc"}
+	if diff := cmp.Diff(want, paths); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
+
+func TestWithExpectedChecksum(t *testing.T) {
+	bin := []byte("#!/bin/sh\necho solc\n")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write(bin)
+	}))
+	defer srv.Close()
+
+	const version Version = "0.0.1"
+	solcVersions[version] = solcVersion{Path: "solc", Sha256: sha256.Sum256(bin)}
+	defer delete(solcVersions, version)
+
+	binSha256 := fmt.Sprintf("%x", sha256.Sum256(bin))
+	otherSha256 := fmt.Sprintf("%x", sha256.Sum256([]byte("other")))
+
+	newCompiler := func(binPath string, opts ...CompilerOption) *Compiler {
+		c := &Compiler{version: version, binPath: binPath, baseURL: srv.URL + "/"}
+		for _, opt := range opts {
+			opt(c)
+		}
+		return c
+	}
+
+	t.Run("download_match", func(t *testing.T) {
+		c := newCompiler(t.TempDir(), WithExpectedChecksum(version, "0x"+binSha256))
+		if _, err := c.checkSolc(); err != nil {
+			t.Fatalf("Failed to check solc: %v", err)
+		}
+	})
+
+	t.Run("download_mismatch", func(t *testing.T) {
+		binPath := t.TempDir()
+		c := newCompiler(binPath, WithExpectedChecksum(version, otherSha256))
+		if _, err := c.checkSolc(); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+			t.Fatalf("want checksum mismatch error, got %v", err)
+		}
+		if fileExists(filepath.Join(binPath, "solc_v"+version.String())) {
+			t.Fatal("want binary not installed")
+		}
+	})
+
+	t.Run("installed_mismatch", func(t *testing.T) {
+		binPath := t.TempDir()
+		if _, err := newCompiler(binPath).checkSolc(); err != nil {
+			t.Fatalf("Failed to check solc: %v", err)
+		}
+
+		for _, offline := range []bool{false, true} {
+			c := newCompiler(binPath, WithExpectedChecksum(version, otherSha256))
+			c.offline = offline
+			if _, err := c.checkSolc(); err == nil || !strings.Contains(err.Error(), "checksum mismatch") {
+				t.Fatalf("offline=%t: want checksum mismatch error, got %v", offline, err)
+			}
+		}
+	})
+
+	t.Run("invalid", func(t *testing.T) {
+		c := newCompiler(t.TempDir(), WithExpectedChecksum(version, "c0fe"))
+		if _, err := c.checkSolc(); err == nil || !strings.Contains(err.Error(), "invalid expected checksum") {
+			t.Fatalf("want invalid checksum error, got %v", err)
+		}
+	})
+}
+
+func TestInstall(t *testing.T) {
+	bin := []byte("#!/bin/sh\necho solc\n")
+	var requests atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		w.Write(bin)
+	}))
+	defer srv.Close()
+
+	const version Version = "0.0.1"
+	solcVersions[version] = solcVersion{Path: "solc", Sha256: sha256.Sum256(bin)}
+	defer delete(solcVersions, version)
+
+	binPath := t.TempDir()
+	for i := 0; i < 2; i++ {
+		got, err := Install(version, binPath, WithDownloadBaseURL(srv.URL))
+		if err != nil {
+			t.Fatalf("Failed to install solc: %v", err)
+		}
+		if got != version {
+			t.Fatalf("want version %s, got %s", version, got)
+		}
+	}
+	if got := requests.Load(); got != 1 {
+		t.Fatalf("want 1 download, got %d", got)
+	}
+	if !fileExists(filepath.Join(binPath, "solc_v"+version.String())) {
+		t.Fatal("want solc installed")
+	}
+}
+
+func TestPrune(t *testing.T) {
+	install := func(t *testing.T, versions ...string) string {
+		binPath := t.TempDir()
+		for _, v := range versions {
+			if err := os.WriteFile(filepath.Join(binPath, "solc_v"+v), []byte(v), 0o755); err != nil {
+				t.Fatal(err)
+			}
+		}
+		return binPath
+	}
+
+	tests := []struct {
+		Name      string
+		Prune     func(binPath string) (PruneResult, error)
+		Want      PruneResult
+		Remaining []Version
+	}{
+		{
+			Name:      "keep_2",
+			Prune:     func(binPath string) (PruneResult, error) { return Prune(binPath, 2) },
+			Want:      PruneResult{Removed: []Version{"0.7.6", "0.8.9"}, Bytes: 10},
+			Remaining: []Version{"0.8.20", "0.8.30"},
+		},
+		{
+			Name:      "keep_all",
+			Prune:     func(binPath string) (PruneResult, error) { return Prune(binPath, 10) },
+			Remaining: []Version{"0.7.6", "0.8.9", "0.8.20", "0.8.30"},
+		},
+		{
+			Name:      "older_than",
+			Prune:     func(binPath string) (PruneResult, error) { return PruneOlderThan(binPath, "0.8.20") },
+			Want:      PruneResult{Removed: []Version{"0.7.6", "0.8.9"}, Bytes: 10},
+			Remaining: []Version{"0.8.20", "0.8.30"},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			binPath := install(t, "0.8.30", "0.7.6", "0.8.20", "0.8.9")
+			got, err := test.Prune(binPath)
+			if err != nil {
+				t.Fatalf("Failed to prune: %v", err)
+			}
+			if diff := cmp.Diff(test.Want, got); diff != "" {
+				t.Fatalf("(-want +got)\n%s", diff)
+			}
+
+			remaining, err := ListInstalled(binPath)
+			if err != nil {
+				t.Fatalf("Failed to list installed versions: %v", err)
+			}
+			if diff := cmp.Diff(test.Remaining, remaining); diff != "" {
+				t.Fatalf("Remaining (-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithDownloadRetries(t *testing.T) {
+	tests := []struct {
+		Status       int // status of the first two requests
+		Retries      int
+		WantErr      bool
+		WantRequests int
+	}{
+		{Status: http.StatusServiceUnavailable, Retries: 2, WantRequests: 3},
+		{Status: http.StatusTooManyRequests, Retries: 2, WantRequests: 3},
+		{Status: http.StatusServiceUnavailable, Retries: 1, WantErr: true, WantRequests: 2},
+		{Status: http.StatusNotFound, Retries: 2, WantErr: true, WantRequests: 1},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			var requests int
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				requests++
+				if requests <= 2 {
+					w.WriteHeader(test.Status)
+					return
+				}
+				w.Write([]byte(`{"builds": [{"version": "0.8.30"}]}`))
+			}))
+			defer srv.Close()
+
+			_, err := AvailableVersions(WithDownloadBaseURL(srv.URL), WithDownloadRetries(test.Retries, time.Millisecond))
+			if gotErr := err != nil; gotErr != test.WantErr {
+				t.Fatalf("want error %t, got %v", test.WantErr, err)
+			}
+			if requests != test.WantRequests {
+				t.Fatalf("want %d requests, got %d", test.WantRequests, requests)
+			}
+		})
+	}
+}
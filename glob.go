@@ -0,0 +1,111 @@
+package solc
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// CompileGlob is like [Compiler.CompileAll] but compiles the source files
+// matching the given pattern, e.g. "contracts/**/*.sol". Besides the syntax of
+// [path.Match], the pattern may contain "**" path elements, which match any
+// number of directories.
+//
+// Source files are named relative to the longest pattern prefix without
+// wildcards, e.g. "contracts/", which is also the directory imports are
+// resolved relative to.
+func (c *Compiler) CompileGlob(pattern string, outputSelection map[string]map[string][]string, opts ...Option) (map[string]map[string]Contract, error) {
+	// build settings
+	s, err := c.buildSettings(outputSelection, opts)
+	if err != nil {
+		return nil, err
+	}
+
+	baseDir, names, err := glob(pattern)
+	if err != nil {
+		return nil, err
+	}
+	if len(names) == 0 {
+		return nil, fmt.Errorf("solc: no source files match %q", pattern)
+	}
+
+	// build src map
+	srcMap := make(map[string]src, len(names))
+	for _, name := range names {
+		srcMap[name] = src{URLS: []string{filepath.Join(baseDir, filepath.FromSlash(name))}}
+	}
+
+	out, err := c.compileSrcMap(context.Background(), baseDir, srcMap, s)
+	if err != nil {
+		return nil, err
+	}
+	contracts, _, err := out.result(s, "")
+	return contracts, err
+}
+
+// glob returns the absolute base directory of the given pattern and the
+// slash-separated paths of all files matching the pattern relative to it.
+func glob(pattern string) (string, []string, error) {
+	elems := strings.Split(filepath.ToSlash(pattern), "/")
+
+	// split the pattern into the base directory and the remaining pattern
+	i := 0
+	for i < len(elems)-1 && !strings.ContainsAny(elems[i], `*?[\`) {
+		i++
+	}
+	base := strings.Join(elems[:i], "/")
+	if base == "" && strings.HasPrefix(pattern, "/") {
+		base = "/"
+	}
+	elems = elems[i:]
+
+	absBase, err := filepath.Abs(filepath.FromSlash(base))
+	if err != nil {
+		return "", nil, err
+	}
+	for _, elem := range elems {
+		if _, err := path.Match(elem, ""); err != nil {
+			return "", nil, fmt.Errorf("solc: invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	var names []string
+	err = fs.WalkDir(os.DirFS(absBase), ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if !d.IsDir() && matchElems(elems, strings.Split(p, "/")) {
+			names = append(names, p)
+		}
+		return nil
+	})
+	if err != nil {
+		return "", nil, err
+	}
+	return absBase, names, nil
+}
+
+// matchElems reports whether the path elements match the pattern elements.
+func matchElems(pattern, elems []string) bool {
+	if len(pattern) == 0 {
+		return len(elems) == 0
+	}
+	if pattern[0] == "**" {
+		// match any number of path elements
+		for i := 0; i <= len(elems); i++ {
+			if matchElems(pattern[1:], elems[i:]) {
+				return true
+			}
+		}
+		return false
+	}
+	if len(elems) == 0 {
+		return false
+	}
+	ok, _ := path.Match(pattern[0], elems[0])
+	return ok && matchElems(pattern[1:], elems[1:])
+}
@@ -0,0 +1,73 @@
+package solc
+
+import (
+	"maps"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompileGlob(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"A.sol": {"A": {}}, "lib/B.sol": {"B": {}}}}`)
+	dir := t.TempDir()
+	if err := os.MkdirAll(filepath.Join(dir, "contracts", "lib", "deep"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	createDummyContract(t, filepath.Join(dir, "contracts"), "A", `import "./lib/B.sol";`)
+	createDummyContract(t, filepath.Join(dir, "contracts", "lib"), "B", "pragma solidity ^0.8.0;")
+	createDummyContract(t, filepath.Join(dir, "contracts", "lib", "deep"), "C", "pragma solidity ^0.8.0;")
+	if err := os.WriteFile(filepath.Join(dir, "contracts", "README.md"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+
+	contracts, err := c.CompileGlob(filepath.Join(dir, "contracts", "**", "*.sol"), nil)
+	if err != nil {
+		t.Fatalf("CompileGlob failed: %v", err)
+	}
+	if len(contracts["A.sol"]) != 1 || len(contracts["lib/B.sol"]) != 1 {
+		t.Fatalf("want all contracts, got %v", contracts)
+	}
+
+	sources := fakeInput(t, c)["sources"].(map[string]any)
+	want := []string{"A.sol", "console.sol", "lib/B.sol", "lib/deep/C.sol"}
+	if diff := cmp.Diff(want, slices.Sorted(maps.Keys(sources))); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+
+	if _, err := c.CompileGlob(filepath.Join(dir, "contracts", "*.vy"), nil); err == nil {
+		t.Fatal("want error if no files match")
+	}
+}
+
+func TestMatchElems(t *testing.T) {
+	tests := []struct {
+		Pattern string
+		Path    string
+		Want    bool
+	}{
+		{Pattern: "*.sol", Path: "A.sol", Want: true},
+		{Pattern: "*.sol", Path: "lib/A.sol", Want: false},
+		{Pattern: "**/*.sol", Path: "A.sol", Want: true},
+		{Pattern: "**/*.sol", Path: "lib/deep/A.sol", Want: true},
+		{Pattern: "lib/**", Path: "lib/deep/A.sol", Want: true},
+		{Pattern: "lib/**/A.sol", Path: "lib/A.sol", Want: true},
+		{Pattern: "lib/**/A.sol", Path: "src/A.sol", Want: false},
+		{Pattern: "**/*.sol", Path: "README.md", Want: false},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got := matchElems(splitSlash(test.Pattern), splitSlash(test.Path))
+			if test.Want != got {
+				t.Fatalf("want %t, got %t", test.Want, got)
+			}
+		})
+	}
+}
+
+func splitSlash(s string) []string { return strings.Split(s, "/") }
@@ -0,0 +1,97 @@
+package solc
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"golang.org/x/sync/errgroup"
+)
+
+// Group is a group of source files of [Compiler.CompileGroups] that is
+// compiled with its own options, e.g. a different EVM version or optimizer.
+type Group struct {
+	Files   []string // Source files, slash-separated and relative to the directory.
+	Options []Option // Options of the group, applied after the common options.
+}
+
+// CompileGroups is like [Compiler.CompileAll] but compiles the given groups of
+// source files in the given directory separately, each with the common options
+// followed by the options of the group, and merges the contracts of the
+// groups' source files. Source files that are imported by a group but not part
+// of it are compiled, but their contracts are not returned. Each source file
+// may only be part of one group.
+//
+// Groups are compiled concurrently, using at most the number of solc
+// processes configured by [WithConcurrency].
+func (c *Compiler) CompileGroups(dir string, groups []Group, outputSelection map[string]map[string][]string, opts ...Option) (map[string]map[string]Contract, error) {
+	groupOf := make(map[string]int) // group index by source file
+	for i, group := range groups {
+		for _, file := range group.Files {
+			if j, ok := groupOf[file]; ok && j != i {
+				return nil, fmt.Errorf("solc: source file %q is part of groups %d and %d", file, j, i)
+			}
+			groupOf[file] = i
+		}
+	}
+
+	results := make([]map[string]map[string]Contract, len(groups))
+	errs := make([]error, len(groups))
+
+	var g errgroup.Group
+	g.SetLimit(c.maxConcurrency())
+	for i, group := range groups {
+		g.Go(func() error {
+			results[i], errs[i] = c.compileGroup(dir, group, outputSelection, opts)
+			if errs[i] != nil {
+				errs[i] = fmt.Errorf("group %d: %w", i, errs[i])
+			}
+			return nil
+		})
+	}
+	g.Wait()
+	if err := errors.Join(errs...); err != nil {
+		return nil, err
+	}
+
+	contracts := make(map[string]map[string]Contract)
+	for i, group := range groups {
+		for _, file := range group.Files {
+			if fileContracts, ok := results[i][file]; ok {
+				contracts[file] = fileContracts
+			}
+		}
+	}
+	return contracts, nil
+}
+
+// compileGroup compiles the source files of the given group in the given
+// directory.
+func (c *Compiler) compileGroup(dir string, group Group, outputSelection map[string]map[string][]string, opts []Option) (map[string]map[string]Contract, error) {
+	// build settings
+	s, err := c.buildSettings(outputSelection, append(slices.Clip(opts), group.Options...))
+	if err != nil {
+		return nil, err
+	}
+
+	absDir, dirSrcMap, err := loadDir(dir, s.lang.ext())
+	if err != nil {
+		return nil, err
+	}
+	srcMap := make(map[string]src, len(group.Files))
+	for _, file := range group.Files {
+		source, ok := dirSrcMap[file]
+		if !ok {
+			return nil, fmt.Errorf("solc: source file %q not found in %s", file, dir)
+		}
+		srcMap[file] = source
+	}
+
+	out, err := c.compileSrcMap(context.Background(), absDir, srcMap, s)
+	if err != nil {
+		return nil, err
+	}
+	contracts, _, err := out.result(s, "")
+	return contracts, err
+}
@@ -0,0 +1,52 @@
+package solc
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestCompileGroups(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"A.sol": {"A": {}}, "B.sol": {"B": {}}, "Lib.sol": {"Lib": {}}}}`)
+	c.concurrency = 1 // compile groups in order
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "A", "contract A {}")
+	createDummyContract(t, srcDir, "B", `import "./Lib.sol";`)
+	createDummyContract(t, srcDir, "Lib", "library Lib {}")
+
+	contracts, err := c.CompileGroups(srcDir, []Group{
+		{Files: []string{"A.sol"}, Options: []Option{WithEVMVersion(EVMVersionShanghai)}},
+		{Files: []string{"B.sol"}, Options: []Option{WithEVMVersion(EVMVersionParis)}},
+	}, nil)
+	if err != nil {
+		t.Fatalf("CompileGroups failed: %v", err)
+	}
+	if diff := cmp.Diff([]string{"A.sol", "B.sol"}, slices.Sorted(maps.Keys(contracts))); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+
+	in := fakeInput(t, c)
+	if got := in["settings"].(map[string]any)["evmVersion"]; got != string(EVMVersionParis) {
+		t.Fatalf("want EVM version of the last group, got %v", got)
+	}
+	if sources := in["sources"].(map[string]any); len(sources) != 2 { // including console.sol
+		t.Fatalf("want only sources of the last group, got %v", sources)
+	}
+
+	t.Run("overlap", func(t *testing.T) {
+		_, err := c.CompileGroups(srcDir, []Group{{Files: []string{"A.sol"}}, {Files: []string{"A.sol"}}}, nil)
+		if err == nil || !strings.Contains(err.Error(), "groups 0 and 1") {
+			t.Fatalf("want overlap error, got %v", err)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := c.CompileGroups(srcDir, []Group{{Files: []string{"Missing.sol"}}}, nil)
+		if err == nil || !strings.Contains(err.Error(), "Missing.sol") {
+			t.Fatalf("want missing file error, got %v", err)
+		}
+	})
+}
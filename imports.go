@@ -0,0 +1,309 @@
+package solc
+
+import (
+	"fmt"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+)
+
+var importRegexp = regexp.MustCompile(`\bimport\s+(?:[^;"']*?\bfrom\s+)?["']([^"']+)["']`)
+
+// parseImports returns the import paths of the given Solidity source in order
+// of appearance.
+func parseImports(source string) []string {
+	var imports []string
+	for _, imp := range parseImportLines(source) {
+		imports = append(imports, imp.path)
+	}
+	return imports
+}
+
+// sourceImport is an import statement of a Solidity source.
+type sourceImport struct {
+	path string // Import path.
+	line int    // Line of the statement, starting at 1.
+}
+
+// parseImportLines is like [parseImports] but additionally returns the line of
+// each import statement.
+func parseImportLines(source string) []sourceImport {
+	// replace comments by their line breaks to keep line numbers
+	source = commentRegexp.ReplaceAllStringFunc(source, func(comment string) string {
+		return strings.Repeat("\n", strings.Count(comment, "\n"))
+	})
+
+	var imports []sourceImport
+	for _, match := range importRegexp.FindAllStringSubmatchIndex(source, -1) {
+		imports = append(imports, sourceImport{
+			path: source[match[2]:match[3]],
+			line: strings.Count(source[:match[0]], "\n") + 1,
+		})
+	}
+	return imports
+}
+
+// resolveImport returns the source unit name of the given import path in the
+// source with the given name: relative imports are resolved against the
+// directory of the importing source and the longest matching remapping prefix
+// is replaced by its target. Remapping contexts are ignored.
+func resolveImport(name, imp string, remappings []string) string {
+	if strings.HasPrefix(imp, "./") || strings.HasPrefix(imp, "../") {
+		imp = path.Join(path.Dir(name), imp)
+	}
+
+	var prefix, target string
+	for _, remap := range remappings {
+		from, to, ok := strings.Cut(remap, "=")
+		if !ok {
+			continue
+		}
+		if _, f, ok := strings.Cut(from, ":"); ok {
+			from = f // strip context
+		}
+		if strings.HasPrefix(imp, from) && len(from) > len(prefix) {
+			prefix, target = from, to
+		}
+	}
+	if prefix != "" {
+		imp = target + strings.TrimPrefix(imp, prefix)
+	}
+	return imp
+}
+
+// checkImports checks that all imports of the given in-memory sources resolve
+// to one of the sources.
+func checkImports(srcMap map[string]src, remappings []string) error {
+	var missing []string
+	for _, name := range slices.Sorted(maps.Keys(srcMap)) {
+		source := srcMap[name]
+		if len(source.URLS) > 0 {
+			return fmt.Errorf("solc: source %q is not given by content", name)
+		}
+		for _, imp := range parseImports(source.Content) {
+			if _, ok := srcMap[resolveImport(name, imp, remappings)]; !ok {
+				missing = append(missing, fmt.Sprintf("%s: %q", name, imp))
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return fmt.Errorf("solc: imports not found in sources\n%s", strings.Join(missing, "\n"))
+	}
+	return nil
+}
+
+// checkFilesystemImports checks that all imports of the given sources and of
+// the files they import resolve to one of the sources or to a file, see
+// [importClosure]. The error lists each unresolved import with the importing
+// source and line.
+func checkFilesystemImports(baseDir string, srcMap map[string]src, remappings, lookupDirs []string) error {
+	if _, missing := importClosure(baseDir, srcMap, remappings, lookupDirs); len(missing) > 0 {
+		return fmt.Errorf("solc: unresolved imports\n%s", strings.Join(missing, "\n"))
+	}
+	return nil
+}
+
+// importClosure returns the content of the files solc reads itself when
+// compiling the given sources, i.e. the files imported by the sources, directly
+// or via other imported files, that are not sources themselves, keyed by source
+// unit name. Imported files are read by absolute path or relative to one of the
+// given lookup directories, see [lookupDirs].
+//
+// Imports that don't resolve to a source or a file are returned as missing,
+// with the importing source and line.
+func importClosure(baseDir string, srcMap map[string]src, remappings, lookupDirs []string) (map[string]string, []string) {
+	type file struct{ name, content string }
+
+	var queue []file
+	for _, name := range slices.Sorted(maps.Keys(srcMap)) {
+		queue = append(queue, file{name, readSource(baseDir, srcMap[name]).Content})
+	}
+
+	var missing []string
+	files := make(map[string]string) // imported files outside the sources
+	for len(queue) > 0 {
+		f := queue[0]
+		queue = queue[1:]
+
+		for _, imp := range parseImportLines(f.content) {
+			name := resolveImport(f.name, imp.path, remappings)
+			if _, ok := srcMap[name]; ok {
+				continue
+			}
+			if _, ok := files[name]; ok {
+				continue
+			}
+
+			var urls []string
+			if filepath.IsAbs(filepath.FromSlash(name)) {
+				urls = []string{filepath.FromSlash(name)}
+			}
+			for _, dir := range lookupDirs {
+				urls = append(urls, filepath.Join(dir, filepath.FromSlash(name)))
+			}
+			source := readSource("", src{URLS: urls})
+			if len(source.URLS) > 0 {
+				missing = append(missing, fmt.Sprintf("%s:%d: %q", f.name, imp.line, imp.path))
+				continue
+			}
+			files[name] = source.Content
+			queue = append(queue, file{name, source.Content})
+		}
+	}
+	return files, missing
+}
+
+// lookupDirs returns the directories solc looks up imported files in when run
+// in the given base directory with the given base path and include paths:
+// the base path, or the base directory if there is none, followed by the
+// include paths. Relative paths are resolved against the base directory.
+func lookupDirs(baseDir, basePath string, includePaths []string) []string {
+	abs := func(path string) string {
+		if filepath.IsAbs(path) {
+			return path
+		}
+		return filepath.Join(baseDir, path)
+	}
+
+	dirs := []string{baseDir}
+	if basePath != "" {
+		dirs[0] = abs(basePath)
+	}
+	for _, includePath := range includePaths {
+		dirs = append(dirs, abs(includePath))
+	}
+	return dirs
+}
+
+// argsLookupDirs is like [lookupDirs] but takes the base path and include
+// paths from the given solc arguments.
+func argsLookupDirs(baseDir string, args []string) []string {
+	var (
+		basePath     string
+		includePaths []string
+	)
+	for i := 0; i+1 < len(args); i++ {
+		switch args[i] {
+		case "--base-path":
+			basePath = args[i+1]
+		case "--include-path":
+			includePaths = append(includePaths, args[i+1])
+		}
+	}
+	return lookupDirs(baseDir, basePath, includePaths)
+}
+
+// nodeModulesRemappings returns remappings of the packages imported by the given
+// sources, directly or via other packages, to the nearest node_modules
+// directory of the given base directory or its parents. Targets are relative
+// to the first lookup directory, i.e. the base path, to keep them independent of
+// the location of the project, e.g.
+// "@openzeppelin/contracts/=../node_modules/@openzeppelin/contracts/".
+//
+// Imports are not remapped if they are relative, match one of the given
+// remappings or refer to a file relative to one of the given lookup
+// directories, i.e. base path and include paths.
+func nodeModulesRemappings(baseDir string, srcMap map[string]src, remappings, lookupDirs []string) []string {
+	nodeModules, ok := findNodeModules(baseDir)
+	if !ok {
+		return nil
+	}
+
+	var (
+		pkgs  = make(map[string]struct{}) // remapped packages
+		seen  = make(map[string]struct{}) // visited files in node_modules
+		queue []string                    // files in node_modules to visit
+	)
+	visit := func(fileDir, content string) {
+		for _, imp := range parseImports(content) {
+			if strings.HasPrefix(imp, "./") || strings.HasPrefix(imp, "../") {
+				if fileDir != "" {
+					queue = append(queue, filepath.Join(fileDir, filepath.FromSlash(imp)))
+				}
+				continue
+			}
+
+			pkg, ok := importPackage(imp)
+			if !ok || resolveImport("", imp, remappings) != imp {
+				continue
+			}
+			if slices.ContainsFunc(lookupDirs, func(dir string) bool {
+				return fileExists(filepath.Join(dir, filepath.FromSlash(imp)))
+			}) {
+				continue
+			}
+			if stat, err := os.Stat(filepath.Join(nodeModules, filepath.FromSlash(pkg))); err != nil || !stat.IsDir() {
+				continue
+			}
+			pkgs[pkg] = struct{}{}
+			queue = append(queue, filepath.Join(nodeModules, filepath.FromSlash(imp)))
+		}
+	}
+
+	// project sources import each other by path, so only their package
+	// imports are followed
+	for _, name := range slices.Sorted(maps.Keys(srcMap)) {
+		visit("", readSource(baseDir, srcMap[name]).Content)
+	}
+	for len(queue) > 0 {
+		file := queue[0]
+		queue = queue[1:]
+		if _, ok := seen[file]; ok {
+			continue
+		}
+		seen[file] = struct{}{}
+
+		content, err := os.ReadFile(file)
+		if err != nil {
+			continue
+		}
+		visit(filepath.Dir(file), string(content))
+	}
+
+	var nodeRemappings []string
+	for _, pkg := range slices.Sorted(maps.Keys(pkgs)) {
+		target := filepath.Join(nodeModules, filepath.FromSlash(pkg))
+		if rel, err := filepath.Rel(lookupDirs[0], target); err == nil {
+			target = rel
+		}
+		target = filepath.ToSlash(target)
+		nodeRemappings = append(nodeRemappings, pkg+"/="+target+"/")
+	}
+	return nodeRemappings
+}
+
+// findNodeModules returns the nearest node_modules directory of the given
+// directory or its parents, or false if there is none.
+func findNodeModules(dir string) (string, bool) {
+	for {
+		nodeModules := filepath.Join(dir, "node_modules")
+		if stat, err := os.Stat(nodeModules); err == nil && stat.IsDir() {
+			return nodeModules, true
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return "", false
+		}
+		dir = parent
+	}
+}
+
+// importPackage returns the package of the given bare import path, e.g.
+// "@openzeppelin/contracts" for "@openzeppelin/contracts/token/ERC20/ERC20.sol"
+// or "solmate" for "solmate/src/tokens/ERC20.sol", or false if the import path
+// does not refer to a file in a package.
+func importPackage(imp string) (string, bool) {
+	n := 1
+	if strings.HasPrefix(imp, "@") {
+		n = 2 // scoped package
+	}
+	elems := strings.Split(imp, "/")
+	if len(elems) <= n || slices.Contains(elems[:n], "") {
+		return "", false
+	}
+	return strings.Join(elems[:n], "/"), true
+}
@@ -0,0 +1,162 @@
+package solc
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestParseImports(t *testing.T) {
+	source := `// SPDX-License-Identifier: MIT
+pragma solidity ^0.8.0;
+
+import "./A.sol";
+import './B.sol' as B;
+import * as C from "../C.sol";
+import {D, E as F} from "@lib/D.sol";
+// import "Commented.sol";
+/* import "Commented2.sol"; */
+
+contract Test {}
+`
+	want := []string{"./A.sol", "./B.sol", "../C.sol", "@lib/D.sol"}
+	if diff := cmp.Diff(want, parseImports(source)); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
+
+func TestResolveImport(t *testing.T) {
+	remappings := []string{"@lib/=lib/", "@lib/sub/=other/", "ctx:@x/=x/"}
+
+	tests := []struct {
+		Name, Import string
+		Want         string
+	}{
+		{Name: "src/Test.sol", Import: "./A.sol", Want: "src/A.sol"},
+		{Name: "src/Test.sol", Import: "../A.sol", Want: "A.sol"},
+		{Name: "src/Test.sol", Import: "src/A.sol", Want: "src/A.sol"},
+		{Name: "Test.sol", Import: "@lib/D.sol", Want: "lib/D.sol"},
+		{Name: "Test.sol", Import: "@lib/sub/D.sol", Want: "other/D.sol"},
+		{Name: "Test.sol", Import: "@x/D.sol", Want: "x/D.sol"},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := resolveImport(test.Name, test.Import, remappings); got != test.Want {
+				t.Fatalf("want %q, got %q", test.Want, got)
+			}
+		})
+	}
+}
+
+func TestNodeModulesRemappings(t *testing.T) {
+	root := t.TempDir()
+	files := map[string]string{
+		"node_modules/@oz/contracts/token/T.sol": `import "../utils/U.sol";`,
+		"node_modules/@oz/contracts/utils/U.sol": `import "solmate/src/S.sol";`,
+		"node_modules/solmate/src/S.sol":         "",
+		"node_modules/unused/src/X.sol":          "",
+		"project/lib/L.sol":                      "",
+		"project/A.sol": `import "@oz/contracts/token/T.sol";
+import "lib/L.sol";
+import "remapped/R.sol";
+import "missing/M.sol";`,
+	}
+	for name, content := range files {
+		path := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	projectDir := filepath.Join(root, "project")
+	srcMap := map[string]src{
+		"A.sol":     {URLS: []string{filepath.Join(projectDir, "A.sol")}},
+		"lib/L.sol": {URLS: []string{filepath.Join(projectDir, "lib", "L.sol")}},
+	}
+	got := nodeModulesRemappings(projectDir, srcMap, []string{"remapped/=other/"}, []string{projectDir})
+
+	want := []string{
+		"@oz/contracts/=../node_modules/@oz/contracts/",
+		"solmate/=../node_modules/solmate/",
+	}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+
+	// explicit remappings take precedence
+	got = nodeModulesRemappings(projectDir, srcMap, []string{"@oz/=other/"}, []string{projectDir})
+	if diff := cmp.Diff([]string(nil), got); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
+
+func TestImportPackage(t *testing.T) {
+	tests := []struct {
+		Import  string
+		WantPkg string
+		WantOk  bool
+	}{
+		{"@oz/contracts/token/T.sol", "@oz/contracts", true},
+		{"solmate/src/S.sol", "solmate", true},
+		{"S.sol", "", false},
+		{"@oz/T.sol", "", false},
+		{"/abs/S.sol", "", false},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			pkg, ok := importPackage(test.Import)
+			if pkg != test.WantPkg || ok != test.WantOk {
+				t.Fatalf("want %q, %t, got %q, %t", test.WantPkg, test.WantOk, pkg, ok)
+			}
+		})
+	}
+}
+
+func TestParseImportLines(t *testing.T) {
+	source := `pragma solidity ^0.8.0;
+/* import "Commented.sol";
+*/
+import "./A.sol";
+
+import {B} from
+	"./B.sol";`
+
+	want := []sourceImport{{path: "./A.sol", line: 4}, {path: "./B.sol", line: 6}}
+	if diff := cmp.Diff(want, parseImportLines(source), cmp.AllowUnexported(sourceImport{})); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
+
+func TestCheckFilesystemImports(t *testing.T) {
+	srcDir, libDir := t.TempDir(), t.TempDir()
+	createDummyContract(t, srcDir, "A", "import \"./B.sol\";\nimport \"lib/Lib.sol\";\nimport \"./Missing.sol\";")
+	createDummyContract(t, srcDir, "B", "")
+	createDummyContract(t, libDir, "Lib", "\nimport \"./Missing2.sol\";")
+
+	srcMap := map[string]src{
+		"A.sol": {URLS: []string{filepath.Join(srcDir, "A.sol")}},
+		"B.sol": {URLS: []string{filepath.Join(srcDir, "B.sol")}},
+	}
+	remappings := []string{"lib/=" + filepath.ToSlash(libDir) + "/"}
+	err := checkFilesystemImports(srcDir, srcMap, remappings, []string{srcDir})
+	want := "solc: unresolved imports\n" +
+		"A.sol:3: \"./Missing.sol\"\n" +
+		filepath.ToSlash(libDir) + "/Lib.sol:2: \"./Missing2.sol\""
+	if err == nil || err.Error() != want {
+		t.Fatalf("want error %q, got %v", want, err)
+	}
+
+	createDummyContract(t, srcDir, "Missing", "")
+	createDummyContract(t, libDir, "Missing2", "")
+	if err := checkFilesystemImports(srcDir, srcMap, remappings, []string{srcDir}); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+}
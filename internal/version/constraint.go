@@ -0,0 +1,235 @@
+package version
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Constraint is a version constraint as used in Solidity's version pragma,
+// e.g. "^0.8.0", ">=0.7.0 <0.9.0" or "0.8.19 || ^0.8.24".
+type Constraint struct {
+	str  string
+	sets [][]comparator // satisfied if all comparators of any set are satisfied
+}
+
+// ParseConstraint parses the given version constraint.
+func ParseConstraint(s string) (*Constraint, error) {
+	c := &Constraint{str: strings.TrimSpace(s)}
+	for _, setStr := range strings.Split(s, "||") {
+		set, err := parseSet(setStr)
+		if err != nil {
+			return nil, fmt.Errorf("invalid version constraint %q: %w", s, err)
+		}
+		c.sets = append(c.sets, set)
+	}
+	return c, nil
+}
+
+// Check returns true if the given version satisfies the constraint. Invalid
+// versions never satisfy the constraint.
+func (c *Constraint) Check(v string) bool {
+	ver, err := parse(v)
+	if err != nil || ver.n != 3 {
+		return false
+	}
+
+	for _, set := range c.sets {
+		ok := true
+		for _, cmp := range set {
+			if !cmp.check(ver.parts) {
+				ok = false
+				break
+			}
+		}
+		if ok {
+			return true
+		}
+	}
+	return false
+}
+
+func (c *Constraint) String() string { return c.str }
+
+// comparator compares a version against a fixed version.
+type comparator struct {
+	op      string // one of "=", ">", ">=", "<", "<="
+	version [3]int
+}
+
+func (cmp comparator) check(v [3]int) bool {
+	d := compareParts(v, cmp.version)
+	switch cmp.op {
+	case "=":
+		return d == 0
+	case ">":
+		return d > 0
+	case ">=":
+		return d >= 0
+	case "<":
+		return d < 0
+	case "<=":
+		return d <= 0
+	}
+	return false
+}
+
+// parseSet parses a space separated list of comparators.
+func parseSet(s string) ([]comparator, error) {
+	fields := strings.Fields(s)
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("empty constraint")
+	}
+
+	// hyphen range, e.g. "0.8.0 - 0.8.10"
+	if len(fields) == 3 && fields[1] == "-" {
+		lo, err := parse(fields[0])
+		if err != nil {
+			return nil, err
+		}
+		hi, err := parse(fields[2])
+		if err != nil {
+			return nil, err
+		}
+		set := []comparator{{">=", lo.parts}}
+		if hi.n == 0 {
+			// wildcard, e.g. "0.8.0 - *"
+			return set, nil
+		}
+		if hi.n == 3 {
+			return append(set, comparator{"<=", hi.parts}), nil
+		}
+		return append(set, comparator{"<", hi.bump()}), nil
+	}
+
+	// join operators that are separated from their version, e.g. ">= 0.8.0"
+	var tokens []string
+	for i := 0; i < len(fields); i++ {
+		if strings.Trim(fields[i], "^~<>=") == "" && i+1 < len(fields) {
+			tokens = append(tokens, fields[i]+fields[i+1])
+			i++
+			continue
+		}
+		tokens = append(tokens, fields[i])
+	}
+
+	var set []comparator
+	for _, token := range tokens {
+		cmps, err := parseComparator(token)
+		if err != nil {
+			return nil, err
+		}
+		set = append(set, cmps...)
+	}
+	return set, nil
+}
+
+// parseComparator parses a single comparator token, e.g. "^0.8.0", into one or
+// more primitive comparators.
+func parseComparator(s string) ([]comparator, error) {
+	var op string
+	for _, prefix := range []string{">=", "<=", ">", "<", "=", "^", "~"} {
+		if rest, ok := strings.CutPrefix(s, prefix); ok {
+			op, s = prefix, rest
+			break
+		}
+	}
+
+	v, err := parse(s)
+	if err != nil {
+		return nil, err
+	}
+	if v.n == 0 {
+		// wildcard, e.g. "*"
+		return []comparator{{">=", [3]int{}}}, nil
+	}
+
+	switch op {
+	case "^":
+		// allow changes that do not modify the left-most non-zero part
+		hi := [3]int{}
+		switch {
+		case v.parts[0] > 0 || v.n == 1:
+			hi[0] = v.parts[0] + 1
+		case v.parts[1] > 0 || v.n == 2:
+			hi[1] = v.parts[1] + 1
+		default:
+			hi[2] = v.parts[2] + 1
+		}
+		return []comparator{{">=", v.parts}, {"<", hi}}, nil
+	case "~":
+		// allow patch level changes, or minor level changes if only the major
+		// part is given
+		hi := [3]int{v.parts[0] + 1}
+		if v.n > 1 {
+			hi = [3]int{v.parts[0], v.parts[1] + 1}
+		}
+		return []comparator{{">=", v.parts}, {"<", hi}}, nil
+	case ">":
+		if v.n < 3 {
+			return []comparator{{">=", v.bump()}}, nil
+		}
+		return []comparator{{">", v.parts}}, nil
+	case "<=":
+		if v.n < 3 {
+			return []comparator{{"<", v.bump()}}, nil
+		}
+		return []comparator{{"<=", v.parts}}, nil
+	case ">=", "<":
+		return []comparator{{op, v.parts}}, nil
+	default: // "=" or no operator
+		if v.n < 3 {
+			return []comparator{{">=", v.parts}, {"<", v.bump()}}, nil
+		}
+		return []comparator{{"=", v.parts}}, nil
+	}
+}
+
+// partial is a possibly partial version, e.g. "0.8".
+type partial struct {
+	parts [3]int
+	n     int // number of given parts
+}
+
+// bump returns the smallest version greater than all versions matching the
+// partial version.
+func (p partial) bump() [3]int {
+	var v [3]int
+	copy(v[:p.n], p.parts[:p.n])
+	v[p.n-1]++
+	return v
+}
+
+func parse(s string) (partial, error) {
+	var p partial
+	if s == "" {
+		return p, fmt.Errorf("empty version")
+	}
+
+	for i, part := range strings.Split(s, ".") {
+		if part == "*" || part == "x" || part == "X" {
+			break
+		}
+		if i >= 3 {
+			return p, fmt.Errorf("invalid version %q", s)
+		}
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return p, fmt.Errorf("invalid version %q", s)
+		}
+		p.parts[i] = n
+		p.n++
+	}
+	return p, nil
+}
+
+func compareParts(x, y [3]int) int {
+	for i := range x {
+		if x[i] < y[i] {
+			return -1
+		} else if x[i] > y[i] {
+			return 1
+		}
+	}
+	return 0
+}
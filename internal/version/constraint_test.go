@@ -0,0 +1,75 @@
+package version_test
+
+import (
+	"strconv"
+	"testing"
+
+	"github.com/raszia/go-solc/internal/version"
+)
+
+func TestConstraintCheck(t *testing.T) {
+	tests := []struct {
+		Constraint string
+		Version    string
+		Want       bool
+	}{
+		{"^0.8.0", "0.8.0", true},
+		{"^0.8.0", "0.8.30", true},
+		{"^0.8.0", "0.9.0", false},
+		{"^0.8.0", "0.7.6", false},
+		{"^0.8", "0.8.30", true},
+		{"^0.0.3", "0.0.3", true},
+		{"^0.0.3", "0.0.4", false},
+		{"^1.2.3", "1.9.0", true},
+		{"~0.8.1", "0.8.30", true},
+		{"~0.8.1", "0.8.0", false},
+		{"~0.8.1", "0.9.0", false},
+		{"0.8.19", "0.8.19", true},
+		{"0.8.19", "0.8.20", false},
+		{"=0.8.19", "0.8.19", true},
+		{"0.8", "0.8.5", true},
+		{"0.8.x", "0.9.0", false},
+		{">=0.7.0 <0.9.0", "0.8.30", true},
+		{">=0.7.0 <0.9.0", "0.9.0", false},
+		{">= 0.7.0 < 0.9.0", "0.6.12", false},
+		{">0.8.0", "0.8.0", false},
+		{">0.8", "0.8.30", false},
+		{">0.8", "0.9.0", true},
+		{"<=0.8", "0.8.30", true},
+		{"<=0.8", "0.9.0", false},
+		{"0.8.0 - 0.8.10", "0.8.10", true},
+		{"0.8.0 - 0.8.10", "0.8.11", false},
+		{"0.8.0 - 0.8", "0.8.11", true},
+		{"0.8.0 - *", "1.0.0", true},
+		{"0.8.0 - *", "0.7.6", false},
+		{"0.7.6 || ^0.8.20", "0.7.6", true},
+		{"0.7.6 || ^0.8.20", "0.8.19", false},
+		{"0.7.6 || ^0.8.20", "0.8.20", true},
+		{"*", "0.5.0", true},
+		{"^0.8.0", "0.8", false},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c, err := version.ParseConstraint(test.Constraint)
+			if err != nil {
+				t.Fatalf("Failed to parse constraint: %v", err)
+			}
+			if got := c.Check(test.Version); test.Want != got {
+				t.Fatalf("%q satisfies %q: want %t, got %t", test.Version, test.Constraint, test.Want, got)
+			}
+		})
+	}
+}
+
+func TestParseConstraintInvalid(t *testing.T) {
+	tests := []string{"", "^", "0.8.a", "0.8.0.1", ">=0.8.0 ||", "v0.8.0"}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if _, err := version.ParseConstraint(test); err == nil {
+				t.Fatalf("want error for %q", test)
+			}
+		})
+	}
+}
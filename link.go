@@ -0,0 +1,170 @@
+package solc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var placeholderRegexp = regexp.MustCompile(`__\$[0-9a-f]{34}\$__`)
+
+// libraryPlaceholder returns the placeholder of the library with the given
+// fully qualified name, e.g. "Lib.sol:Lib", in unlinked bytecode.
+func libraryPlaceholder(name string) string {
+	hash := crypto.Keccak256Hash([]byte(name)).Hex()[2:36]
+	return "__$" + hash + "$__"
+}
+
+// Link replaces the placeholders of the given libraries in the hex encoded
+// bytecode by their addresses. Libraries are keyed by their fully qualified
+// name, e.g. "Lib.sol:Lib". An error is returned if the bytecode still contains
+// placeholders after linking.
+//
+// The missing libraries are named after the given link references of the
+// compile result, e.g. [Bytecode.LinkReferences]. Placeholders of libraries not
+// in the link references are listed as is.
+func Link(bytecode string, libs map[string]string, linkRefs ...map[string]map[string][]LinkRef) (string, error) {
+	for _, name := range slices.Sorted(maps.Keys(libs)) {
+		addr := libs[name]
+		if !isHexAddress(addr) {
+			return "", fmt.Errorf("solc: invalid address %q for library %s", addr, name)
+		}
+		bytecode = strings.ReplaceAll(bytecode, libraryPlaceholder(name), strings.ToLower(addr[2:]))
+	}
+
+	placeholders := placeholderRegexp.FindAllString(bytecode, -1)
+	if len(placeholders) == 0 {
+		return bytecode, nil
+	}
+
+	names := make(map[string]string) // by placeholder
+	for _, refs := range linkRefs {
+		for file, fileRefs := range refs {
+			for lib := range fileRefs {
+				names[libraryPlaceholder(file+":"+lib)] = file + ":" + lib
+			}
+		}
+	}
+	missing := make([]string, 0, len(placeholders))
+	for _, placeholder := range placeholders {
+		if name, ok := names[placeholder]; ok {
+			placeholder = name
+		}
+		missing = append(missing, placeholder)
+	}
+	slices.Sort(missing)
+	return "", fmt.Errorf("solc: bytecode contains unlinked libraries: %s",
+		strings.Join(slices.Compact(missing), ", "))
+}
+
+// Link returns a copy of the bytecode with the references of the given
+// libraries replaced by their addresses, using the link references of the
+// bytecode. Libraries are keyed by their fully qualified name, e.g.
+// "Lib.sol:Lib". An error listing the missing libraries is returned if not all
+// libraries referenced by the bytecode are given.
+func (b Bytecode) Link(libs map[string]string) (Bytecode, error) {
+	obj := bytes.Clone(b.Object)
+
+	var missing []string
+	for _, file := range slices.Sorted(maps.Keys(b.LinkReferences)) {
+		for _, lib := range slices.Sorted(maps.Keys(b.LinkReferences[file])) {
+			name := file + ":" + lib
+			addr, ok := libs[name]
+			if !ok {
+				missing = append(missing, name)
+				continue
+			}
+			if !isHexAddress(addr) {
+				return Bytecode{}, fmt.Errorf("solc: invalid address %q for library %s", addr, name)
+			}
+
+			for _, ref := range b.LinkReferences[file][lib] {
+				if ref.Length != common.AddressLength || ref.Start < 0 || ref.Start+ref.Length > len(obj) {
+					return Bytecode{}, fmt.Errorf("solc: invalid link reference of library %s", name)
+				}
+				copy(obj[ref.Start:], common.HexToAddress(addr).Bytes())
+			}
+		}
+	}
+	if len(missing) > 0 {
+		return Bytecode{}, fmt.Errorf("solc: missing addresses of libraries: %s", strings.Join(missing, ", "))
+	}
+
+	linked := b
+	linked.Object = obj
+	linked.LinkReferences = nil
+	return linked, nil
+}
+
+// setPlaceholderLinkReferences sets the link references of the unlinked
+// libraries of the contracts of the output from the placeholders in the raw
+// output, unless solc reported them, i.e. if "evm.bytecode.linkReferences" is
+// not selected. Placeholders are matched against the contracts of the output.
+// An error is returned if a placeholder matches no contract.
+func (o *Output) setPlaceholderLinkReferences() error {
+	if !bytes.Contains(o.Raw, []byte("__$")) {
+		return nil
+	}
+
+	type object struct {
+		Object string `json:"object"`
+	}
+	var raw struct {
+		Contracts map[string]map[string]struct {
+			EVM struct {
+				Bytecode         object `json:"bytecode"`
+				DeployedBytecode object `json:"deployedBytecode"`
+			} `json:"evm"`
+		} `json:"contracts"`
+	}
+	if err := json.Unmarshal(o.Raw, &raw); err != nil {
+		return err
+	}
+
+	type library struct{ file, name string }
+	libs := make(map[string]library) // by placeholder
+	for file, contracts := range o.Contracts {
+		for name := range contracts {
+			libs[libraryPlaceholder(file+":"+name)] = library{file, name}
+		}
+	}
+
+	for file, contracts := range raw.Contracts {
+		for name, rawContract := range contracts {
+			contract := o.Contracts[file][name]
+			for _, b := range []struct {
+				obj      string
+				bytecode *Bytecode
+			}{
+				{rawContract.EVM.Bytecode.Object, &contract.EVM.Bytecode},
+				{rawContract.EVM.DeployedBytecode.Object, &contract.EVM.DeployedBytecode},
+			} {
+				locs := placeholderRegexp.FindAllStringIndex(b.obj, -1)
+				if len(locs) == 0 || len(b.bytecode.LinkReferences) > 0 {
+					continue
+				}
+				b.bytecode.LinkReferences = make(map[string]map[string][]LinkRef)
+				for _, loc := range locs {
+					lib, ok := libs[b.obj[loc[0]:loc[1]]]
+					if !ok {
+						return fmt.Errorf("solc: unknown library placeholder %q in bytecode of %s:%s", b.obj[loc[0]:loc[1]], file, name)
+					}
+					if b.bytecode.LinkReferences[lib.file] == nil {
+						b.bytecode.LinkReferences[lib.file] = make(map[string][]LinkRef)
+					}
+					b.bytecode.LinkReferences[lib.file][lib.name] = append(b.bytecode.LinkReferences[lib.file][lib.name],
+						LinkRef{Start: loc[0] / 2, Length: common.AddressLength})
+				}
+			}
+			o.Contracts[file][name] = contract
+		}
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package solc
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestLink(t *testing.T) {
+	const (
+		name = "Lib.sol:Lib"
+		addr = "0x00000000000000000000000000000000000000Aa"
+	)
+	placeholder := libraryPlaceholder(name)
+	bytecode := "73" + placeholder + "6080" + placeholder
+
+	t.Run("linked", func(t *testing.T) {
+		got, err := Link(bytecode, map[string]string{name: addr})
+		if err != nil {
+			t.Fatalf("Failed to link: %v", err)
+		}
+		want := "73" + "00000000000000000000000000000000000000aa" + "6080" + "00000000000000000000000000000000000000aa"
+		if got != want {
+			t.Fatalf("want %s, got %s", want, got)
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := Link(bytecode, map[string]string{"Other.sol:Other": addr})
+		if err == nil || !strings.Contains(err.Error(), placeholder) {
+			t.Fatalf("want error listing %s, got %v", placeholder, err)
+		}
+	})
+
+	t.Run("missing_link_references", func(t *testing.T) {
+		linkRefs := map[string]map[string][]LinkRef{"Lib.sol": {"Lib": {{Start: 1, Length: 20}, {Start: 23, Length: 20}}}}
+		_, err := Link(bytecode, nil, linkRefs)
+		if err == nil || err.Error() != "solc: bytecode contains unlinked libraries: "+name {
+			t.Fatalf("want error listing %s, got %v", name, err)
+		}
+	})
+
+	t.Run("invalid_address", func(t *testing.T) {
+		if _, err := Link(bytecode, map[string]string{name: "0x1234"}); err == nil {
+			t.Fatal("want error for invalid address")
+		}
+	})
+}
+
+func TestBytecodeLink(t *testing.T) {
+	const addr = "0x00000000000000000000000000000000000000aa"
+
+	b := Bytecode{
+		Object: append(append(append([]byte{0x73}, make([]byte, 20)...), 0x60, 0x80), make([]byte, 20)...),
+		LinkReferences: map[string]map[string][]LinkRef{
+			"Lib.sol": {
+				"Lib":   {{Start: 1, Length: 20}},
+				"Other": {{Start: 23, Length: 20}},
+			},
+		},
+	}
+
+	t.Run("linked", func(t *testing.T) {
+		got, err := b.Link(map[string]string{"Lib.sol:Lib": addr, "Lib.sol:Other": addr})
+		if err != nil {
+			t.Fatalf("Failed to link: %v", err)
+		}
+		want := Bytecode{Object: mustDecodeHex(t, "73"+addr[2:]+"6080"+addr[2:])}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("(-want +got)\n%s", diff)
+		}
+
+		// the original bytecode is not modified
+		if b.Object[1] != 0 {
+			t.Fatal("want original bytecode unmodified")
+		}
+	})
+
+	t.Run("missing", func(t *testing.T) {
+		_, err := b.Link(map[string]string{"Lib.sol:Lib": addr})
+		if err == nil || !strings.Contains(err.Error(), "Lib.sol:Other") {
+			t.Fatalf("want error listing Lib.sol:Other, got %v", err)
+		}
+	})
+}
+
+func TestCompilePlaceholderLinkReferences(t *testing.T) {
+	object := "73" + libraryPlaceholder("Lib.sol:Lib") + "6080"
+	c := newFakeCompiler(t, `{"contracts": {
+		"Lib.sol": {"Lib": {"evm": {"bytecode": {"object": "6001"}}}},
+		"Test.sol": {"Test": {"evm": {"bytecode": {"object": "`+object+`"}}}}
+	}}`)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	contracts, err := c.Compile(srcDir, "Test", nil)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	want := map[string]map[string][]LinkRef{"Lib.sol": {"Lib": {{Start: 1, Length: 20}}}}
+	if diff := cmp.Diff(want, contracts["Test.sol"]["Test"].EVM.Bytecode.LinkReferences); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
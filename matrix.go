@@ -0,0 +1,83 @@
+package solc
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// MatrixError is returned by [Compiler.CompileMatrix] if the compilation failed
+// for some of the versions.
+type MatrixError struct {
+	Errs map[Version]error // Keyed by solc version.
+}
+
+func (e *MatrixError) Error() string {
+	versions := slices.SortedFunc(maps.Keys(e.Errs), Version.Cmp)
+	msgs := make([]string, len(versions))
+	for i, v := range versions {
+		msgs[i] = fmt.Sprintf("%s: %v", v, e.Errs[v])
+	}
+	return fmt.Sprintf("solc: compilation failed for %d version(s)\n", len(versions)) + strings.Join(msgs, "\n")
+}
+
+// CompileMatrix is like [Compiler.Compile] but compiles the given directory
+// with each of the given solc versions, e.g. to compare the outputs of
+// different versions. The solc binaries of all versions are looked up or
+// downloaded like the binary of the compiler, using the bin directory and
+// [CompilerOption]s of the compiler.
+//
+// The returned contracts are keyed by solc version. If the compilation fails for
+// some versions, the contracts of all other versions are returned together with
+// a [*MatrixError] that holds the error of each failed version.
+func (c *Compiler) CompileMatrix(versions []Version, dir, contract string, outputSelection map[string]map[string][]string, opts ...Option) (map[Version]map[string]map[string]Contract, error) {
+	results := make(map[Version]map[string]map[string]Contract, len(versions))
+	errs := make(map[Version]error)
+	for _, version := range versions {
+		vc, err := c.withVersion(version)
+		if err != nil {
+			errs[version] = err
+			continue
+		}
+		contracts, err := vc.Compile(dir, contract, outputSelection, opts...)
+		if err != nil {
+			errs[version] = err
+			continue
+		}
+		results[version] = contracts
+	}
+
+	if len(errs) > 0 {
+		return results, &MatrixError{Errs: errs}
+	}
+	return results, nil
+}
+
+// withVersion returns a new [Compiler] for the given solc version with the
+// same configuration as c.
+func (c *Compiler) withVersion(version Version) (*Compiler, error) {
+	vc := &Compiler{
+		binPath:  c.binPath,
+		offline:  c.offline,
+		baseURL:  c.baseURL,
+		cache:    c.cache,
+		progress: c.progress,
+		client:   c.client,
+		retry:    c.retry,
+		logger:   c.logger,
+
+		checksums:   c.checksums,
+		solJSRunner: c.solJSRunner,
+		concurrency: c.concurrency,
+	}
+
+	var err error
+	if vc.version, err = vc.resolveVersion(version); err != nil {
+		return nil, err
+	}
+	if vc.solcAbsPath, err = vc.checkSolc(); err != nil {
+		return nil, err
+	}
+	return vc, nil
+}
@@ -0,0 +1,44 @@
+package solc
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestCompileMatrix(t *testing.T) {
+	binPath := t.TempDir()
+	for _, version := range []Version{"0.8.29", "0.8.30"} {
+		script := "#!/bin/sh\ncat > /dev/null\n" +
+			`echo '{"contracts": {"Test.sol": {"Test": {"metadata": "` + version.String() + `"}}}}'` + "\n"
+		if err := os.WriteFile(filepath.Join(binPath, "solc_v"+version.String()), []byte(script), 0o755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	cacheMux.Lock()
+	clear(cache)
+	cacheMux.Unlock()
+
+	c, err := New("0.8.30", binPath, WithOffline())
+	if err != nil {
+		t.Fatalf("Failed to create compiler: %v", err)
+	}
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+
+	results, err := c.CompileMatrix([]Version{"0.8.28", "0.8.29", "0.8.30"}, srcDir, "Test", nil)
+
+	var matrixErr *MatrixError
+	if !errors.As(err, &matrixErr) || len(matrixErr.Errs) != 1 || matrixErr.Errs["0.8.28"] == nil {
+		t.Fatalf("want error of version 0.8.28 only, got %v", err)
+	}
+	if len(results) != 2 {
+		t.Fatalf("want results of 2 versions, got %v", results)
+	}
+	for _, version := range []Version{"0.8.29", "0.8.30"} {
+		if got := results[version]["Test.sol"]["Test"].Metadata; got != version.String() {
+			t.Fatalf("want output of %s, got %q", version, got)
+		}
+	}
+}
@@ -1,5 +1,12 @@
 package solc
 
+import (
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
 // default settings options
 var (
 	DefaultLang                     = langSolidity
@@ -84,9 +91,156 @@ var (
 	}
 )
 
+// minViaIRVersion is the first solc version with non-experimental support for
+// compiling via IR.
+const minViaIRVersion Version = "0.8.13"
+
+// minIncludePathVersion is the first solc version supporting include paths.
+const minIncludePathVersion Version = "0.8.8"
+
+// minOptimizerDetailsVersion is the first solc version supporting optimizer
+// details.
+const minOptimizerDetailsVersion Version = "0.5.5"
+
+// minYulDetailsVersion is the first solc version supporting Yul optimizer
+// details.
+const minYulDetailsVersion Version = "0.6.0"
+
+// minBytecodeHashVersion is the first solc version supporting the metadata
+// bytecode hash.
+const minBytecodeHashVersion Version = "0.6.0"
+
+// maxErrorRecoveryVersion is the last solc version supporting parser error
+// recovery, which was removed in solc 0.8.21.
+const maxErrorRecoveryVersion Version = "0.8.20"
+
+// A CompilerOption configures a [Compiler].
+type CompilerOption func(*Compiler)
+
+// WithOffline configures the [Compiler] to only use solc binaries that are
+// already installed. In offline mode no network requests are made and
+// version constraints are resolved using the installed versions only.
+func WithOffline() CompilerOption {
+	return func(c *Compiler) {
+		c.offline = true
+	}
+}
+
+// WithDownloadBaseURL configures the [Compiler] to download the version list
+// and solc binaries from the given base URL, e.g. of an internal mirror,
+// instead of https://binaries.soliditylang.org/{platform}/.
+//
+// The mirror must preserve the structure of solc-bin relative to the base
+// URL, i.e. it must serve "{baseURL}/list.json" and the binaries at the paths
+// listed in it. soljson.js builds, see [WithSolJSRunner], are downloaded from
+// the sibling directory "{baseURL}/../bin/".
+func WithDownloadBaseURL(url string) CompilerOption {
+	return func(c *Compiler) {
+		c.baseURL = strings.TrimSuffix(url, "/") + "/"
+	}
+}
+
+// WithDownloadProgress sets a callback that is called with the number of bytes
+// downloaded so far and the total size of the solc binary while it is
+// downloaded. If the total size is unknown, total is -1.
+func WithDownloadProgress(fn func(downloaded, total int64)) CompilerOption {
+	return func(c *Compiler) {
+		c.progress = fn
+	}
+}
+
+// WithHTTPClient sets the HTTP client to download the version list and solc
+// binaries with, e.g. to configure a proxy or custom TLS root certificates. By
+// default, a client with a timeout of 10 minutes per request is used.
+func WithHTTPClient(client *http.Client) CompilerOption {
+	return func(c *Compiler) {
+		c.client = client
+	}
+}
+
+// WithDownloadRetries configures the [Compiler] to retry failed downloads of
+// the version list and solc binaries up to n times, waiting baseDelay before
+// the first retry and doubling the delay before each further retry. Only
+// transient errors are retried, i.e. network errors and HTTP 5xx and 429
+// responses, but e.g. not a 404 of an unknown version. By default, a failed
+// download is attempted [MaxRetryDownloadAttempts] times in total without
+// delay.
+func WithDownloadRetries(n int, baseDelay time.Duration) CompilerOption {
+	return func(c *Compiler) {
+		c.retry = &retryPolicy{retries: max(n, 0), baseDelay: baseDelay}
+	}
+}
+
+// WithLogger sets the logger the [Compiler] reports its activity to, such as
+// version resolution, downloads, cache hits and misses and solc runs. By
+// default, nothing is logged.
+func WithLogger(logger *slog.Logger) CompilerOption {
+	return func(c *Compiler) {
+		c.logger = logger
+	}
+}
+
+// WithCache sets the persistent [Cache] of compilation outputs, e.g. to share
+// outputs between machines. By default, outputs are cached in a [DiskCache] in
+// the bin directory.
+func WithCache(cache Cache) CompilerOption {
+	return func(c *Compiler) {
+		c.cache = cache
+	}
+}
+
+// WithExpectedChecksum pins the hex encoded SHA256 checksum of the solc binary
+// of the given version. The [Compiler] only uses an installed binary of the
+// version if its checksum matches, and only installs a downloaded binary if
+// its checksum matches, even if the published version list lists a different
+// checksum. Multiple versions may be pinned by repeating the option.
+func WithExpectedChecksum(version Version, sha256 string) CompilerOption {
+	return func(c *Compiler) {
+		if c.checksums == nil {
+			c.checksums = make(map[Version]string)
+		}
+		c.checksums[version] = sha256
+	}
+}
+
+// WithSolJSRunner sets the runner of the soljson.js (emscripten) build of
+// solc, which is used as fallback on platforms without native solc binaries,
+// e.g. linux/arm64. On such platforms, the [Compiler] downloads
+// "soljson_v<version>.js" to the bin directory instead of a native binary and
+// compiles by calling the runner, e.g. a function running a small script with
+// Node.js that loads the file using the "solc/wrapper" package.
+//
+// On platforms with native solc binaries, the runner is not used.
+func WithSolJSRunner(runner SolJSRunner) CompilerOption {
+	return func(c *Compiler) {
+		c.solJSRunner = runner
+	}
+}
+
+// WithConcurrency sets the maximum number of solc processes that
+// [Compiler.CompileMany] runs concurrently. By default, the number of CPUs is
+// used.
+func WithConcurrency(n int) CompilerOption {
+	return func(c *Compiler) {
+		c.concurrency = n
+	}
+}
+
 // An Option configures the compilation [Settings].
 type Option func(*Settings)
 
+// WithLanguage configures the compilation [Settings] to set the language of
+// the sources, either "Solidity" (default) or "Yul". When compiling a
+// directory, only source files of the language (".sol" or ".yul") are
+// compiled.
+//
+// solc compiles a single Yul source at a time. Yul contracts have no ABI.
+func WithLanguage(language string) Option {
+	return func(s *Settings) {
+		s.lang = lang(language)
+	}
+}
+
 // WithOptimizer configures the compilation [Settings] to set the given
 // [Optimizer].
 func WithOptimizer(o *Optimizer) Option {
@@ -95,26 +249,277 @@ func WithOptimizer(o *Optimizer) Option {
 	}
 }
 
+// OptimizerForSize returns an [Optimizer] that minimizes the deployment cost,
+// i.e. the size of the bytecode, by optimizing for 1 run.
+func OptimizerForSize() *Optimizer { return &Optimizer{Enabled: true, Runs: 1} }
+
+// OptimizerBalanced returns an [Optimizer] that balances deployment and runtime
+// cost with solc's default of 200 runs.
+func OptimizerBalanced() *Optimizer { return &Optimizer{Enabled: true, Runs: 200} }
+
+// OptimizerForRuntime returns an [Optimizer] that minimizes the runtime cost of
+// frequently called contracts by optimizing for 999999 runs, at the expense of
+// a larger bytecode.
+func OptimizerForRuntime() *Optimizer { return &Optimizer{Enabled: true, Runs: 999999} }
+
+// WithOptimizerDetails configures the compilation [Settings] to set the given
+// [OptimizerDetails]. Unlike [WithOptimizer], the other optimizer settings are
+// kept, so the details also apply if the optimizer is disabled:
+//
+//	noYul := false
+//	opts := []solc.Option{
+//		solc.WithOptimizer(&solc.Optimizer{Enabled: false}),
+//		solc.WithOptimizerDetails(&solc.OptimizerDetails{Yul: &noYul}),
+//	}
+func WithOptimizerDetails(details *OptimizerDetails) Option {
+	return func(s *Settings) {
+		// copy the optimizer, as it may be shared, e.g. [DefaultOptimizer]
+		o := new(Optimizer)
+		if s.Optimizer != nil {
+			*o = *s.Optimizer
+		}
+		o.Details = details
+		s.Optimizer = o
+	}
+}
+
 // WithViaIR configures the compilation [Settings] to set viaIR to the given
 // parameter "enabled".
+//
+// Compiling via IR requires solc 0.8.13 or later.
 func WithViaIR(enabled bool) Option {
 	return func(s *Settings) {
 		s.ViaIR = enabled
 	}
 }
 
+// WithInputTransformer configures the compilation [Settings] to apply the given
+// function to the standard JSON input before it is passed to solc. The function
+// receives the decoded input and returns the input to use instead, e.g. to set
+// settings not supported by an [Option]:
+//
+//	solc.WithInputTransformer(func(in map[string]any) map[string]any {
+//		in["settings"].(map[string]any)["experimental"] = true
+//		return in
+//	})
+//
+// The transformed input is also used to compute the cache key.
+func WithInputTransformer(fn func(map[string]any) map[string]any) Option {
+	return func(s *Settings) {
+		s.transform = fn
+	}
+}
+
+// StopAfterParsing is the compilation stage for [WithStopAfter] that stops
+// after parsing the source files.
+const StopAfterParsing = "parsing"
+
+// WithStopAfter configures the compilation [Settings] to stop after the given
+// stage. The only supported stage is [StopAfterParsing], which only checks the
+// syntax of the source files without generating bytecode.
+//
+// Unless an output selection is given, only the AST of each source file is
+// selected, see [Output.Sources]. Contracts have no outputs.
+func WithStopAfter(stage string) Option {
+	return func(s *Settings) {
+		s.StopAfter = stage
+	}
+}
+
 // WithEVMVersion configures the compilation [Settings] to set the given EVM
 // version.
+//
+// The EVM version is validated when compiling. An unknown EVM version, or one
+// not supported by the solc version of the compiler, results in a compilation
+// error without invoking solc.
 func WithEVMVersion(evmVersion EVMVersion) Option {
 	return func(s *Settings) {
 		s.EVMVersion = evmVersion
 	}
 }
 
-// WithRemappings configures the compilation [Settings] to set the remmappings options.
-// Each remapping is in standard format {src}={dst}. Destination path needs to be absolute.
+// WithRemappings configures the compilation [Settings] to set the remappings.
+// Each remapping is in the standard format "[context:]prefix=target", e.g.
+// "@openzeppelin/=/abs/path/node_modules/@openzeppelin/". The target path needs
+// to be absolute.
+//
+// The target of each remapping is added to the paths solc is allowed to read
+// from, so imports outside of the source directory resolve. Targets are not
+// checked for existence: if a target does not exist on disk, imports resolved
+// through it fail with solc's "File not found" compilation error.
 func WithRemappings(remappings []string) Option {
 	return func(s *Settings) {
 		s.Remappings = remappings
 	}
 }
+
+// WithStrictWarnings configures the compilation to fail if solc reports any
+// warnings. The returned error lists all errors and warnings. Diagnostics with
+// severity "info" never fail the compilation.
+func WithStrictWarnings() Option {
+	return func(s *Settings) {
+		s.strictWarnings = true
+	}
+}
+
+// WithErrorRecovery configures the compilation [Settings] to let the parser of
+// solc recover from syntax errors, like "solc --error-recovery", such that as
+// many errors as possible are reported instead of only the first one.
+//
+// If the compilation fails, the partial output is returned together with the
+// error, e.g. the diagnostics by [Compiler.CompileWithDiagnostics] or the
+// output by [Compiler.CompileOutput].
+//
+// Error recovery was removed in solc 0.8.21, so it requires solc 0.8.20 or
+// earlier.
+func WithErrorRecovery() Option {
+	return func(s *Settings) {
+		s.ParserErrorRecovery = true
+	}
+}
+
+// WithLibraries configures the compilation [Settings] to link the given
+// libraries. The outer key is the source file, the inner key is the library
+// name and the value is the 0x-prefixed address of the deployed library.
+//
+// Library addresses are validated when compiling.
+func WithLibraries(libs map[string]map[string]string) Option {
+	return func(s *Settings) {
+		s.Libraries = libs
+	}
+}
+
+// WithMetadataHash configures the compilation [Settings] to append the given
+// hash of the metadata to the bytecode. Use [BytecodeHashNone] to not append
+// any metadata hash.
+//
+// The bytecode hash is validated when compiling. Configuring the bytecode hash
+// requires solc 0.6.0 or later.
+func WithMetadataHash(hash BytecodeHash) Option {
+	return func(s *Settings) {
+		if s.Metadata == nil {
+			s.Metadata = new(Metadata)
+		}
+		s.Metadata.BytecodeHash = hash
+	}
+}
+
+// WithMetadataLiteral configures the compilation [Settings] to embed the
+// content of the sources in the metadata instead of only referencing them by
+// hash.
+func WithMetadataLiteral(enabled bool) Option {
+	return func(s *Settings) {
+		if s.Metadata == nil {
+			s.Metadata = new(Metadata)
+		}
+		s.Metadata.UseLiteralContent = enabled
+	}
+}
+
+// WithBasePath configures the compilation to resolve imports relative to the
+// given base path (solc's --base-path) instead of the source directory. The
+// base path is added to the paths solc is allowed to read from.
+//
+// Remappings are applied to import paths first. The resulting path is then
+// resolved relative to the base path and the include paths.
+func WithBasePath(path string) Option {
+	return func(s *Settings) {
+		s.basePath = path
+	}
+}
+
+// WithIncludePaths configures the compilation to additionally resolve imports
+// relative to the given include paths (solc's --include-path), e.g. a
+// node_modules directory. The include paths are added to the paths solc is
+// allowed to read from.
+//
+// Include paths require a base path (see [WithBasePath]) and solc 0.8.8 or
+// later.
+func WithIncludePaths(paths ...string) Option {
+	return func(s *Settings) {
+		s.includePaths = append(s.includePaths, paths...)
+	}
+}
+
+// WithAllowedPaths restricts the paths solc is allowed to read sources from
+// (solc's --allow-paths) to the given paths, e.g. when compiling untrusted
+// sources.
+//
+// By default, solc is allowed to read from the source directory, the base path
+// and include paths (see [WithBasePath] and [WithIncludePaths]) and the target
+// of each remapping. If allowed paths are set, the remapping targets are no
+// longer allowed, unless they are among the given paths. The source directory,
+// base path and include paths are always allowed.
+func WithAllowedPaths(paths ...string) Option {
+	return func(s *Settings) {
+		s.restrictPaths = true
+		s.allowedPaths = append(s.allowedPaths, paths...)
+	}
+}
+
+// WithNoFilesystem configures the compilation to not read any files, e.g.
+// when compiling untrusted sources. All sources, including imported ones, must
+// be given by content using [Compiler.CompileSources]. Imports that don't
+// resolve to one of the sources fail before solc is run.
+//
+// solc is run in an empty temporary directory and is not allowed to read from
+// any path. Base path, include paths and allowed paths are ignored.
+func WithNoFilesystem() Option {
+	return func(s *Settings) {
+		s.noFilesystem = true
+	}
+}
+
+// WithTimeout configures the compilation to kill solc if it does not complete
+// within the given duration. The compilation then fails with an error that
+// wraps [context.DeadlineExceeded].
+func WithTimeout(d time.Duration) Option {
+	return func(s *Settings) {
+		s.timeout = d
+	}
+}
+
+// WithImportCheck configures the compilation to resolve the imports of all
+// sources, and of the files they import, before solc is run, using the
+// remappings, the base path and the include paths. If an import does not
+// resolve to a source or an existing file, an error listing each unresolved
+// import with the importing file and line is returned instead of solc's
+// error.
+//
+// Imports of in-memory sources compiled with [WithNoFilesystem] are always
+// checked.
+func WithImportCheck() Option {
+	return func(s *Settings) {
+		s.checkImports = true
+	}
+}
+
+// WithReturnSources configures the compilation to return the content of all
+// compiled source files, including imported files, in [SourceOutput.Content]
+// (see [Compiler.CompileOutput]), e.g. to submit the exact sources for
+// verification.
+func WithReturnSources() Option {
+	return func(s *Settings) {
+		s.returnSources = true
+	}
+}
+
+// WithExtraArgs configures the compilation to append the given arguments to the
+// solc command line, e.g. to use a flag that is not supported by an [Option]
+// yet. The arguments are passed verbatim and may conflict with the arguments
+// of this package, e.g. with solc's standard JSON mode. They are ignored if
+// solc is run as soljson.js (see [WithSolJSRunner]).
+func WithExtraArgs(args ...string) Option {
+	return func(s *Settings) {
+		s.extraArgs = append(s.extraArgs, args...)
+	}
+}
+
+// WithArtifacts configures the compilation [Settings] to select the given
+// artifacts of all contracts (see [OutputArtifacts]). The option only applies
+// if no output selection is passed to the compile method.
+func WithArtifacts(kinds ...ArtifactKind) Option {
+	return func(s *Settings) {
+		s.OutputSelection = OutputArtifacts(kinds...)
+	}
+}
@@ -1,6 +1,13 @@
 package solc
 
-import "testing"
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
 
 func TestDefaultEVMVersions(t *testing.T) {
 	if len(solcVersions) == 0 {
@@ -13,3 +20,459 @@ func TestDefaultEVMVersions(t *testing.T) {
 		}
 	}
 }
+
+func TestWithEVMVersion(t *testing.T) {
+	c := &Compiler{version: VersionLatest}
+
+	t.Run("valid", func(t *testing.T) {
+		s, err := c.buildSettings(nil, []Option{WithEVMVersion(EVMVersionParis)})
+		if err != nil {
+			t.Fatalf("Failed to build settings: %v", err)
+		}
+		if s.EVMVersion != EVMVersionParis {
+			t.Fatalf("want %q, got %q", EVMVersionParis, s.EVMVersion)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := c.buildSettings(nil, []Option{WithEVMVersion("frontier2")})
+		if err == nil || !strings.Contains(err.Error(), `"frontier2"`) {
+			t.Fatalf("want unknown EVM version error, got %v", err)
+		}
+	})
+
+	t.Run("cache_key", func(t *testing.T) {
+		keys := make(map[string]struct{})
+		for _, evmVersion := range []EVMVersion{EVMVersionLondon, EVMVersionShanghai} {
+			s, err := c.buildSettings(nil, []Option{WithEVMVersion(evmVersion)})
+			if err != nil {
+				t.Fatalf("Failed to build settings: %v", err)
+			}
+			key, err := c.cacheKey(&input{Lang: s.lang, Settings: s}, nil)
+			if err != nil {
+				t.Fatalf("Failed to compute cache key: %v", err)
+			}
+			keys[key] = struct{}{}
+		}
+		if len(keys) != 2 {
+			t.Fatal("want distinct cache keys for distinct EVM versions")
+		}
+	})
+}
+
+func TestWithViaIR(t *testing.T) {
+	tests := []struct {
+		Version Version
+		WantErr bool
+	}{
+		{"0.8.12", true},
+		{"0.8.13", false},
+		{VersionLatest, false},
+	}
+
+	for _, test := range tests {
+		t.Run(string(test.Version), func(t *testing.T) {
+			c := &Compiler{version: test.Version}
+			s, err := c.buildSettings(nil, []Option{WithViaIR(true)})
+			if gotErr := err != nil; test.WantErr != gotErr {
+				t.Fatalf("want error %t, got %v", test.WantErr, err)
+			}
+			if err == nil && !s.ViaIR {
+				t.Fatal("want viaIR enabled")
+			}
+		})
+	}
+}
+
+func TestWithLibraries(t *testing.T) {
+	c := &Compiler{version: VersionLatest}
+
+	tests := []struct {
+		Name    string
+		Addr    string
+		WantErr bool
+	}{
+		{"valid", "0x000000000000000000000000000000000000c0DE", false},
+		{"no_prefix", "000000000000000000000000000000000000c0DE", true},
+		{"short", "0xc0DE", true},
+		{"non_hex", "0x000000000000000000000000000000000000c0DX", true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.Name, func(t *testing.T) {
+			libs := map[string]map[string]string{
+				"Lib.sol": {"Lib": test.Addr},
+			}
+			s, err := c.buildSettings(nil, []Option{WithLibraries(libs)})
+			if gotErr := err != nil; test.WantErr != gotErr {
+				t.Fatalf("want error %t, got %v", test.WantErr, err)
+			}
+			if err != nil {
+				if !strings.Contains(err.Error(), "Lib.sol:Lib") {
+					t.Fatalf("want error naming the library, got %v", err)
+				}
+				return
+			}
+			if s.Libraries["Lib.sol"]["Lib"] != test.Addr {
+				t.Fatalf("want library address %q, got %q", test.Addr, s.Libraries["Lib.sol"]["Lib"])
+			}
+		})
+	}
+}
+
+func TestWithRemappings(t *testing.T) {
+	c := &Compiler{version: VersionLatest}
+	s, err := c.buildSettings(nil, []Option{WithRemappings([]string{
+		"@openzeppelin/=/lib/node_modules/@openzeppelin/",
+		"ctx:ds-test/=/lib/ds-test/src/",
+		"invalid",
+	})})
+	if err != nil {
+		t.Fatalf("Failed to build settings: %v", err)
+	}
+
+	got := allowPaths("/src", s)
+	want := []string{"/src", "/lib/node_modules/@openzeppelin/", "/lib/ds-test/src/"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
+
+func TestOptimizerJSON(t *testing.T) {
+	yes, no := true, false
+
+	tests := []struct {
+		Optimizer *Optimizer
+		Want      string
+	}{
+		{
+			Optimizer: &Optimizer{Enabled: true, Runs: 200},
+			Want:      `{"enabled":true,"runs":200}`,
+		},
+		{
+			Optimizer: &Optimizer{Enabled: true, Runs: 200, Details: &OptimizerDetails{
+				Yul:        &yes,
+				CSE:        &no,
+				YulDetails: &YulDetails{StackAllocation: &yes},
+			}},
+			Want: `{"enabled":true,"runs":200,"details":{"cse":false,"yul":true,"yulDetails":{"stackAllocation":true}}}`,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := json.Marshal(test.Optimizer)
+			if err != nil {
+				t.Fatalf("Failed to marshal optimizer: %v", err)
+			}
+			if test.Want != string(got) {
+				t.Fatalf("want %s, got %s", test.Want, got)
+			}
+		})
+	}
+}
+
+func TestWithMetadataHash(t *testing.T) {
+	c := &Compiler{version: VersionLatest}
+
+	t.Run("valid", func(t *testing.T) {
+		s, err := c.buildSettings(nil, []Option{WithMetadataHash(BytecodeHashNone)})
+		if err != nil {
+			t.Fatalf("Failed to build settings: %v", err)
+		}
+		got, err := json.Marshal(s.Metadata)
+		if err != nil {
+			t.Fatalf("Failed to marshal metadata: %v", err)
+		}
+		if want := `{"bytecodeHash":"none"}`; want != string(got) {
+			t.Fatalf("want %s, got %s", want, got)
+		}
+	})
+
+	t.Run("unknown", func(t *testing.T) {
+		_, err := c.buildSettings(nil, []Option{WithMetadataHash("swarm")})
+		if err == nil || !strings.Contains(err.Error(), `"swarm"`) {
+			t.Fatalf("want unknown bytecode hash error, got %v", err)
+		}
+	})
+}
+
+func TestWithMetadataLiteral(t *testing.T) {
+	c := &Compiler{version: VersionLatest}
+
+	keys := make(map[string]struct{})
+	for _, enabled := range []bool{false, true} {
+		s, err := c.buildSettings(nil, []Option{WithMetadataHash(BytecodeHashIPFS), WithMetadataLiteral(enabled)})
+		if err != nil {
+			t.Fatalf("Failed to build settings: %v", err)
+		}
+		if s.Metadata.UseLiteralContent != enabled || s.Metadata.BytecodeHash != BytecodeHashIPFS {
+			t.Fatalf("unexpected metadata settings: %+v", s.Metadata)
+		}
+
+		key, err := c.cacheKey(&input{Lang: s.lang, Settings: s}, nil)
+		if err != nil {
+			t.Fatalf("Failed to compute cache key: %v", err)
+		}
+		keys[key] = struct{}{}
+	}
+	if len(keys) != 2 {
+		t.Fatal("want distinct cache keys for literal and non-literal metadata")
+	}
+}
+
+func TestWithBasePathAndIncludePaths(t *testing.T) {
+	t.Run("args", func(t *testing.T) {
+		c := &Compiler{version: VersionLatest}
+		s, err := c.buildSettings(nil, []Option{
+			WithBasePath("/repo"),
+			WithIncludePaths("/repo/node_modules", "/repo/lib"),
+		})
+		if err != nil {
+			t.Fatalf("Failed to build settings: %v", err)
+		}
+
+		got := solcArgs("/repo/src", s)
+		want := []string{
+			"--base-path", "/repo",
+			"--include-path", "/repo/node_modules",
+			"--include-path", "/repo/lib",
+			"--allow-paths", "/repo/src,/repo,/repo/node_modules,/repo/lib",
+			"--standard-json",
+		}
+		if diff := cmp.Diff(want, got); diff != "" {
+			t.Fatalf("(-want +got)\n%s", diff)
+		}
+	})
+
+	t.Run("no_base_path", func(t *testing.T) {
+		c := &Compiler{version: VersionLatest}
+		if _, err := c.buildSettings(nil, []Option{WithIncludePaths("/lib")}); err == nil {
+			t.Fatal("want error for include paths without base path")
+		}
+	})
+
+	t.Run("old_version", func(t *testing.T) {
+		c := &Compiler{version: "0.8.7"}
+		if _, err := c.buildSettings(nil, []Option{WithBasePath("/repo"), WithIncludePaths("/lib")}); err == nil {
+			t.Fatal("want error for include paths on solc 0.8.7")
+		}
+	})
+}
+
+func TestWithExtraArgs(t *testing.T) {
+	c := &Compiler{version: VersionLatest}
+	s, err := c.buildSettings(nil, []Option{WithExtraArgs("--via-ssa-cfg"), WithExtraArgs("--foo", "bar")})
+	if err != nil {
+		t.Fatalf("Failed to build settings: %v", err)
+	}
+
+	want := []string{"--allow-paths", "/src", "--standard-json", "--via-ssa-cfg", "--foo", "bar"}
+	if diff := cmp.Diff(want, solcArgs("/src", s)); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+
+	s.noFilesystem = true
+	want = []string{"--standard-json", "--via-ssa-cfg", "--foo", "bar"}
+	if diff := cmp.Diff(want, solcArgs("/src", s)); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
+
+func TestWithArtifacts(t *testing.T) {
+	c := &Compiler{version: VersionLatest}
+
+	t.Run("option", func(t *testing.T) {
+		s, err := c.buildSettings(nil, []Option{WithArtifacts(ArtifactABI, ArtifactMetadata)})
+		if err != nil {
+			t.Fatalf("Failed to build settings: %v", err)
+		}
+		want := map[string]map[string][]string{"*": {"*": {"abi", "metadata"}}}
+		if diff := cmp.Diff(want, s.OutputSelection); diff != "" {
+			t.Fatalf("(-want +got)\n%s", diff)
+		}
+	})
+
+	t.Run("explicit_selection", func(t *testing.T) {
+		s, err := c.buildSettings(OutputABI(), []Option{WithArtifacts(ArtifactMetadata)})
+		if err != nil {
+			t.Fatalf("Failed to build settings: %v", err)
+		}
+		if diff := cmp.Diff(OutputABI(), s.OutputSelection); diff != "" {
+			t.Fatalf("(-want +got)\n%s", diff)
+		}
+	})
+
+	t.Run("default", func(t *testing.T) {
+		s, err := c.buildSettings(nil, nil)
+		if err != nil {
+			t.Fatalf("Failed to build settings: %v", err)
+		}
+		if diff := cmp.Diff(DefaultOutputSelection, s.OutputSelection); diff != "" {
+			t.Fatalf("(-want +got)\n%s", diff)
+		}
+	})
+}
+
+func TestWithAllowedPaths(t *testing.T) {
+	c := &Compiler{version: VersionLatest}
+	remappings := WithRemappings([]string{"@openzeppelin/=/lib/node_modules/@openzeppelin/"})
+
+	tests := []struct {
+		Opts []Option
+		Want []string
+	}{
+		{
+			Opts: []Option{remappings},
+			Want: []string{"/src", "/lib/node_modules/@openzeppelin/"},
+		},
+		{
+			Opts: []Option{remappings, WithAllowedPaths("/lib/ds-test/")},
+			Want: []string{"/src", "/lib/ds-test/"},
+		},
+		{
+			Opts: []Option{remappings, WithAllowedPaths()},
+			Want: []string{"/src"},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			s, err := c.buildSettings(nil, test.Opts)
+			if err != nil {
+				t.Fatalf("Failed to build settings: %v", err)
+			}
+			if diff := cmp.Diff(test.Want, allowPaths("/src", s)); diff != "" {
+				t.Fatalf("(-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestWithStopAfter(t *testing.T) {
+	c := &Compiler{version: VersionLatest}
+
+	s, err := c.buildSettings(nil, []Option{WithStopAfter(StopAfterParsing)})
+	if err != nil {
+		t.Fatalf("Failed to build settings: %v", err)
+	}
+	if s.StopAfter != "parsing" {
+		t.Fatalf("want stopAfter %q, got %q", "parsing", s.StopAfter)
+	}
+	if diff := cmp.Diff(OutputArtifacts(ArtifactAST), s.OutputSelection); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+
+	if _, err := c.buildSettings(nil, []Option{WithStopAfter("analysis")}); err == nil || !strings.Contains(err.Error(), `"analysis"`) {
+		t.Fatalf("want unknown stage error, got %v", err)
+	}
+}
+
+func TestCheckVersionSupport(t *testing.T) {
+	tests := []struct {
+		Version Version
+		Opts    []Option
+		WantErr string
+	}{
+		{Version: "0.8.23", Opts: []Option{WithEVMVersion(EVMVersionCancun)}, WantErr: `EVM version "cancun" requires solc 0.8.24 or later, got 0.8.23`},
+		{Version: "0.8.24", Opts: []Option{WithEVMVersion(EVMVersionCancun)}},
+		{Version: "0.5.4", Opts: []Option{WithEVMVersion(EVMVersionByzantium)}},
+		{Version: "0.8.12", Opts: []Option{WithViaIR(true)}, WantErr: "viaIR requires solc 0.8.13 or later"},
+		{Version: "0.5.17", Opts: []Option{WithMetadataHash(BytecodeHashNone)}, WantErr: "metadata bytecode hash requires solc 0.6.0 or later"},
+		{Version: "0.6.0", Opts: []Option{WithMetadataHash(BytecodeHashNone)}},
+		{Version: "0.8.20", Opts: []Option{WithErrorRecovery()}},
+		{Version: "0.8.21", Opts: []Option{WithErrorRecovery()}, WantErr: "parser error recovery requires solc 0.8.20 or earlier, got 0.8.21"},
+		{
+			Version: "0.5.17",
+			Opts: []Option{func(s *Settings) {
+				s.Optimizer = &Optimizer{Details: &OptimizerDetails{YulDetails: &YulDetails{}}}
+			}},
+			WantErr: "Yul optimizer details requires solc 0.6.0 or later",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			c := &Compiler{version: test.Version}
+			_, err := c.buildSettings(nil, test.Opts)
+			if test.WantErr == "" && err != nil {
+				t.Fatalf("want no error, got %v", err)
+			}
+			if test.WantErr != "" && (err == nil || !strings.Contains(err.Error(), test.WantErr)) {
+				t.Fatalf("want error %q, got %v", test.WantErr, err)
+			}
+		})
+	}
+}
+
+func TestWithOptimizerDetails(t *testing.T) {
+	c := &Compiler{version: VersionLatest}
+	noYul := false
+
+	s, err := c.buildSettings(nil, []Option{
+		WithOptimizer(&Optimizer{Enabled: false}),
+		WithOptimizerDetails(&OptimizerDetails{Yul: &noYul}),
+	})
+	if err != nil {
+		t.Fatalf("Failed to build settings: %v", err)
+	}
+	got, err := json.Marshal(s.Optimizer)
+	if err != nil {
+		t.Fatalf("Failed to marshal optimizer: %v", err)
+	}
+	if want := `{"enabled":false,"runs":0,"details":{"yul":false}}`; want != string(got) {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+
+	// the default optimizer is not modified
+	if _, err := c.buildSettings(nil, []Option{WithOptimizerDetails(&OptimizerDetails{Yul: &noYul})}); err != nil {
+		t.Fatalf("Failed to build settings: %v", err)
+	}
+	if DefaultOptimizer.Details != nil {
+		t.Fatal("want default optimizer without details")
+	}
+
+	keys := make(map[string]struct{})
+	for _, opts := range [][]Option{
+		{WithOptimizer(&Optimizer{Enabled: false})},
+		{WithOptimizer(&Optimizer{Enabled: false}), WithOptimizerDetails(&OptimizerDetails{Yul: &noYul})},
+	} {
+		s, err := c.buildSettings(nil, opts)
+		if err != nil {
+			t.Fatalf("Failed to build settings: %v", err)
+		}
+		key, err := c.cacheKey(&input{Lang: s.lang, Settings: s}, nil)
+		if err != nil {
+			t.Fatalf("Failed to compute cache key: %v", err)
+		}
+		keys[key] = struct{}{}
+	}
+	if len(keys) != 2 {
+		t.Fatal("want distinct cache keys for distinct optimizer details")
+	}
+}
+
+func TestOptimizerPresets(t *testing.T) {
+	tests := []struct {
+		Optimizer *Optimizer
+		WantRuns  uint64
+	}{
+		{Optimizer: OptimizerForSize(), WantRuns: 1},
+		{Optimizer: OptimizerBalanced(), WantRuns: 200},
+		{Optimizer: OptimizerForRuntime(), WantRuns: 999999},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if !test.Optimizer.Enabled || test.Optimizer.Runs != test.WantRuns {
+				t.Fatalf("want enabled optimizer with %d runs, got %+v", test.WantRuns, test.Optimizer)
+			}
+		})
+	}
+
+	// presets are not shared
+	OptimizerForSize().Runs = 2
+	if got := OptimizerForSize().Runs; got != 1 {
+		t.Fatalf("want 1 run, got %d", got)
+	}
+}
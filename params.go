@@ -2,24 +2,107 @@
 package solc
 
 import (
+	"fmt"
+	"strconv"
+	"strings"
+
 	"github.com/raszia/go-solc/internal/version"
 )
 
-var (
-	solcBaseURL  string
-	solcVersions map[Version]solcVersion
-)
+var solcVersions map[Version]solcVersion
 
 // Version represents a solc version.
 type Version string
 
 func (v Version) String() string { return string(v) }
 
-// Compare returns -1, 0, or +1 depending on whether v < other, v == other, or
-// v > other
+// ParseVersion parses a solc version in the format "x.y.z", optionally
+// prefixed by "v" and followed by a pre-release or build suffix as reported by
+// "solc --version", e.g. "0.8.30+commit.73712a01.Linux.g++".
+func ParseVersion(s string) (Version, error) {
+	v := strings.TrimPrefix(s, "v")
+	if i := strings.IndexAny(v, "+-"); i >= 0 {
+		v = v[:i]
+	}
+	if strings.Count(v, ".") != 2 || !version.IsValid(v) {
+		return "", fmt.Errorf("solc: invalid version %q", s)
+	}
+	return Version(v), nil
+}
+
+// Cmp returns -1, 0, or +1 depending on whether v < other, v == other, or
+// v > other.
 func (v Version) Cmp(other Version) int {
 	return version.Compare(string(v), string(other))
 }
 
+// Compare returns -1, 0, or +1 depending on whether v < other, v == other, or
+// v > other. It is equivalent to [Version.Cmp].
+func (v Version) Compare(other Version) int { return v.Cmp(other) }
+
+// Major returns the major version of v, e.g. 0 for "0.8.30".
+func (v Version) Major() int { return v.part(0) }
+
+// Minor returns the minor version of v, e.g. 8 for "0.8.30".
+func (v Version) Minor() int { return v.part(1) }
+
+// Patch returns the patch version of v, e.g. 30 for "0.8.30".
+func (v Version) Patch() int { return v.part(2) }
+
+// part returns the i-th dot-separated part of v, or 0 if v has no such part.
+func (v Version) part(i int) int {
+	parts := strings.Split(string(v), ".")
+	if i >= len(parts) {
+		return 0
+	}
+	n, _ := strconv.Atoi(parts[i])
+	return n
+}
+
 // Versions is a list of all available solc versions.
 var Versions []Version
+
+// Resolve returns the highest version of [Versions] that satisfies the given
+// version constraint, e.g. "^0.8.0" or ">=0.7.0 <0.9.0". The constraint uses
+// the same syntax as Solidity's version pragma.
+//
+// If multiple versions satisfy the constraint, the highest version is
+// returned, i.e. the latest patch release of the highest matching minor
+// release.
+func Resolve(constraint string) (Version, error) {
+	return resolve(constraint, Versions)
+}
+
+// Satisfies reports whether the given version satisfies the given version
+// constraint, e.g. "^0.8.0" or ">=0.7.0 <0.9.0", using the same syntax and
+// semantics as [Resolve]. The version is parsed using [ParseVersion].
+func Satisfies(v Version, constraint string) (bool, error) {
+	c, err := version.ParseConstraint(constraint)
+	if err != nil {
+		return false, fmt.Errorf("solc: %w", err)
+	}
+	if v, err = ParseVersion(string(v)); err != nil {
+		return false, err
+	}
+	return c.Check(string(v)), nil
+}
+
+// resolve returns the highest version of the given versions that satisfies the
+// given version constraint.
+func resolve(constraint string, versions []Version) (Version, error) {
+	c, err := version.ParseConstraint(constraint)
+	if err != nil {
+		return "", fmt.Errorf("solc: %w", err)
+	}
+
+	var best Version
+	for _, v := range versions {
+		if c.Check(string(v)) && (best == "" || v.Cmp(best) > 0) {
+			best = v
+		}
+	}
+	if best == "" {
+		return "", fmt.Errorf("solc: no version satisfies %q", constraint)
+	}
+	return best, nil
+}
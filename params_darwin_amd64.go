@@ -3,8 +3,6 @@
 package solc
 
 func init() {
-	solcBaseURL = "https://binaries.soliditylang.org/macosx-amd64/"
-
 	solcVersions = map[Version]solcVersion{
 		Version0_5_0:  {Sha256: [32]byte{0x9f, 0xa6, 0x63, 0xfc, 0x42, 0x7e, 0x8e, 0x96, 0x13, 0x81, 0x5a, 0xd6, 0xb8, 0x37, 0x83, 0xb4, 0xe4, 0xcb, 0x10, 0x43, 0x9f, 0x16, 0xca, 0x0d, 0xcb, 0x12, 0xfc, 0x00, 0x2c, 0x45, 0xab, 0x5a}, Path: "solc-macosx-amd64-v0.5.0+commit.1d4f565a"},
 		Version0_5_1:  {Sha256: [32]byte{0xf2, 0xba, 0xd4, 0x33, 0x86, 0x06, 0x56, 0x84, 0xb4, 0xe1, 0x5b, 0x86, 0xa1, 0x75, 0x41, 0x0f, 0x5d, 0x1e, 0x4d, 0x23, 0x4b, 0x05, 0x2c, 0xba, 0x44, 0xeb, 0x50, 0xf4, 0xc7, 0x4b, 0x02, 0x1f}, Path: "solc-macosx-amd64-v0.5.1+commit.c8a2cb62"},
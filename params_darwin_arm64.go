@@ -3,8 +3,6 @@
 package solc
 
 func init() {
-	solcBaseURL = "https://binaries.soliditylang.org/macosx-amd64/"
-
 	solcVersions = map[Version]solcVersion{
 		Version0_8_24: {Sha256: [32]byte{0xcc, 0x2d, 0x44, 0xc7, 0x06, 0x90, 0x5c, 0xcc, 0x38, 0x2f, 0x48, 0x46, 0x25, 0xdf, 0xf6, 0x1d, 0x74, 0x1e, 0x0c, 0x24, 0x23, 0x2d, 0x22, 0x6f, 0x13, 0x9a, 0x68, 0x35, 0xfc, 0x64, 0x4f, 0x3f}, Path: "solc-macosx-amd64-v0.8.24+commit.e11b9ed9"},
 		Version0_8_25: {Sha256: [32]byte{0xcc, 0x3f, 0x94, 0xa7, 0x0a, 0xc6, 0x81, 0xb0, 0x30, 0x40, 0x84, 0xac, 0xc1, 0x98, 0x0a, 0xab, 0xe2, 0xa1, 0xbb, 0x32, 0x40, 0xd4, 0x4c, 0xe7, 0x6a, 0x8d, 0xf0, 0xe1, 0xe7, 0x7a, 0x21, 0x10}, Path: "solc-macosx-amd64-v0.8.25+commit.b61c2a91"},
@@ -130,8 +130,6 @@ const tmplStr = `// Code generated by "go generate"; DO NOT EDIT.
 package solc
 
 func init() {
-	solcBaseURL = "{{ .Target.BaseURL }}"
-
 	solcVersions = map[Version]solcVersion{
 	{{- range .Builds }}
 		{{ $version := (printf "Version%s:" (replaceAll .Version "." "_")) -}}
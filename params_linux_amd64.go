@@ -3,8 +3,6 @@
 package solc
 
 func init() {
-	solcBaseURL = "https://binaries.soliditylang.org/linux-amd64/"
-
 	solcVersions = map[Version]solcVersion{
 		Version0_5_0:  {Sha256: [32]byte{0xc1, 0xbb, 0x15, 0xb5, 0x20, 0xf5, 0x07, 0x6a, 0xeb, 0xd7, 0xaa, 0x9e, 0xf4, 0xce, 0x5f, 0xa2, 0x45, 0xb6, 0xf2, 0x10, 0xa9, 0x1c, 0xbd, 0x20, 0x64, 0xb9, 0xe3, 0x83, 0xe6, 0x51, 0x0e, 0x08}, Path: "solc-linux-amd64-v0.5.0+commit.1d4f565a"},
 		Version0_5_1:  {Sha256: [32]byte{0x62, 0x75, 0xd4, 0x81, 0xf2, 0x31, 0x80, 0xe0, 0x0b, 0x38, 0x99, 0x68, 0x48, 0x53, 0x4d, 0xb7, 0x8b, 0x4f, 0x73, 0xca, 0xac, 0xa1, 0x54, 0x35, 0xad, 0x86, 0x1d, 0xf5, 0x45, 0xbb, 0x71, 0xd0}, Path: "solc-linux-amd64-v0.5.1+commit.c8a2cb62"},
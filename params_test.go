@@ -0,0 +1,116 @@
+package solc
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestResolve(t *testing.T) {
+	versions := []Version{"0.7.6", "0.8.0", "0.8.19", "0.8.20", "0.8.30"}
+
+	tests := []struct {
+		Constraint string
+		Want       Version
+		WantErr    bool
+	}{
+		{Constraint: "^0.8.0", Want: "0.8.30"},
+		{Constraint: "^0.7.0", Want: "0.7.6"},
+		{Constraint: ">=0.8.0 <0.8.20", Want: "0.8.19"},
+		{Constraint: "0.8.20", Want: "0.8.20"},
+		{Constraint: "^0.6.0", WantErr: true},
+		{Constraint: "invalid", WantErr: true},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := resolve(test.Constraint, versions)
+			if gotErr := err != nil; test.WantErr != gotErr {
+				t.Fatalf("want error %t, got %v", test.WantErr, err)
+			}
+			if test.Want != got {
+				t.Fatalf("want %q, got %q", test.Want, got)
+			}
+		})
+	}
+}
+
+func TestParseVersion(t *testing.T) {
+	tests := []struct {
+		Version string
+		Want    Version
+		WantErr bool
+	}{
+		{Version: "0.8.30", Want: "0.8.30"},
+		{Version: "v0.8.30", Want: "0.8.30"},
+		{Version: "0.8.30+commit.73712a01.Linux.g++", Want: "0.8.30"},
+		{Version: "0.8.31-nightly.2025.6.1+commit.1234abcd", Want: "0.8.31"},
+		{Version: "0.8", WantErr: true},
+		{Version: "0.8.x", WantErr: true},
+		{Version: "", WantErr: true},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := ParseVersion(test.Version)
+			if gotErr := err != nil; test.WantErr != gotErr {
+				t.Fatalf("want error %t, got %v", test.WantErr, err)
+			}
+			if test.Want != got {
+				t.Fatalf("want %q, got %q", test.Want, got)
+			}
+		})
+	}
+}
+
+func TestSatisfies(t *testing.T) {
+	tests := []struct {
+		Version    Version
+		Constraint string
+		Want       bool
+		WantErr    bool
+	}{
+		{Version: "0.8.19", Constraint: "^0.8.0", Want: true},
+		{Version: "0.8.19", Constraint: "^0.7.0", Want: false},
+		{Version: "0.7.6", Constraint: ">=0.7.0 <0.9.0", Want: true},
+		{Version: "0.9.0", Constraint: ">=0.7.0 <0.9.0", Want: false},
+		{Version: "v0.8.30+commit.73712a01", Constraint: "0.8.30", Want: true},
+		{Version: "0.8", Constraint: "^0.8.0", WantErr: true},
+		{Version: "0.8.19", Constraint: "foo", WantErr: true},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := Satisfies(test.Version, test.Constraint)
+			if gotErr := err != nil; test.WantErr != gotErr {
+				t.Fatalf("want error %t, got %v", test.WantErr, err)
+			}
+			if test.Want != got {
+				t.Fatalf("want %t, got %t", test.Want, got)
+			}
+		})
+	}
+}
+
+func TestVersionParts(t *testing.T) {
+	v := Version("0.8.30")
+	if v.Major() != 0 || v.Minor() != 8 || v.Patch() != 30 {
+		t.Fatalf("want 0.8.30, got %d.%d.%d", v.Major(), v.Minor(), v.Patch())
+	}
+
+	tests := []struct {
+		A, B Version
+		Want int
+	}{
+		{A: "0.8.9", B: "0.8.30", Want: -1},
+		{A: "0.8.30", B: "0.8.30", Want: 0},
+		{A: "0.10.0", B: "0.8.30", Want: 1},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := test.A.Compare(test.B); test.Want != got {
+				t.Fatalf("want %d, got %d", test.Want, got)
+			}
+		})
+	}
+}
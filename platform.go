@@ -0,0 +1,58 @@
+package solc
+
+import (
+	"fmt"
+	"runtime"
+	"strings"
+)
+
+// solcBinURL is the base URL of solc-bin, which serves the solc binaries of
+// each platform in a separate directory.
+const solcBinURL = "https://binaries.soliditylang.org/"
+
+// goos and goarch are the platform to select solc binaries for.
+var goos, goarch = runtime.GOOS, runtime.GOARCH
+
+// solcPlatforms maps GOOS/GOARCH to the platform directory of the solc binaries
+// in solc-bin (https://binaries.soliditylang.org/). On macOS, the amd64
+// binaries are used for arm64, as they are universal binaries since solc
+// 0.8.24.
+var solcPlatforms = map[string]string{
+	"linux/amd64":  "linux-amd64",
+	"darwin/amd64": "macosx-amd64",
+	"darwin/arm64": "macosx-amd64",
+}
+
+// solcPlatform returns the solc-bin platform directory of the given GOOS and
+// GOARCH, e.g. "linux-amd64", or an error if solc-bin has no binaries for the
+// platform.
+func solcPlatform(goos, goarch string) (string, error) {
+	platform, ok := solcPlatforms[goos+"/"+goarch]
+	if !ok {
+		return "", fmt.Errorf("solc: no solc binaries available for platform %s/%s", goos, goarch)
+	}
+	return platform, nil
+}
+
+// solcBinaryName returns the file name of the installed solc binary of the
+// given version, e.g. "solc_v0.8.30".
+func solcBinaryName(version Version) string {
+	return "solc_v" + version.String()
+}
+
+// parseSolcBinaryName returns the version of the installed solc binary with the
+// given file name, or false if the file name is not the name of a solc binary.
+func parseSolcBinaryName(name string) (Version, bool) {
+	v, ok := strings.CutPrefix(name, "solc_v")
+	if !ok {
+		return "", false
+	}
+	return Version(v), true
+}
+
+// checkPlatform returns an error if there are no solc binaries for the current
+// platform.
+func checkPlatform() error {
+	_, err := solcPlatform(goos, goarch)
+	return err
+}
@@ -0,0 +1,67 @@
+package solc
+
+import (
+	"strconv"
+	"testing"
+)
+
+func TestSolcPlatform(t *testing.T) {
+	tests := []struct {
+		GOOS, GOARCH string
+		Want         string
+		WantErr      bool
+	}{
+		{GOOS: "linux", GOARCH: "amd64", Want: "linux-amd64"},
+		{GOOS: "darwin", GOARCH: "amd64", Want: "macosx-amd64"},
+		{GOOS: "darwin", GOARCH: "arm64", Want: "macosx-amd64"},
+		{GOOS: "windows", GOARCH: "amd64", WantErr: true},
+		{GOOS: "linux", GOARCH: "arm64", WantErr: true},
+		{GOOS: "freebsd", GOARCH: "amd64", WantErr: true},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := solcPlatform(test.GOOS, test.GOARCH)
+			if gotErr := err != nil; test.WantErr != gotErr {
+				t.Fatalf("want error %t, got %v", test.WantErr, err)
+			}
+			if test.Want != got {
+				t.Fatalf("want %q, got %q", test.Want, got)
+			}
+		})
+	}
+}
+
+func TestSolcBinaryName(t *testing.T) {
+	got := solcBinaryName("0.8.30")
+	if want := "solc_v0.8.30"; want != got {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+	if v, ok := parseSolcBinaryName(got); !ok || v != "0.8.30" {
+		t.Fatalf("want version 0.8.30, got %q, %t", v, ok)
+	}
+	if _, ok := parseSolcBinaryName("soljson-v0.8.30.js"); ok {
+		t.Fatal("want no version of non-binary file")
+	}
+}
+
+func TestDownloadBaseURL(t *testing.T) {
+	defer func(os, arch string) { goos, goarch = os, arch }(goos, goarch)
+	goos, goarch = "darwin", "arm64"
+
+	got, err := new(Compiler).downloadBaseURL()
+	if err != nil {
+		t.Fatalf("Failed to get download base URL: %v", err)
+	}
+	if want := "https://binaries.soliditylang.org/macosx-amd64/"; want != got {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+
+	goos, goarch = "linux", "arm64"
+	if _, err := new(Compiler).downloadBaseURL(); err == nil {
+		t.Fatal("want error on unsupported platform")
+	}
+	if got, err := (&Compiler{baseURL: "http://mirror/"}).downloadBaseURL(); err != nil || got != "http://mirror/" {
+		t.Fatalf("want mirror base URL, got %q, %v", got, err)
+	}
+}
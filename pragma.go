@@ -0,0 +1,176 @@
+package solc
+
+import (
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/raszia/go-solc/internal/version"
+)
+
+var (
+	commentRegexp = regexp.MustCompile(`(?s)//[^\n]*|/\*.*?\*/`)
+	pragmaRegexp  = regexp.MustCompile(`\bpragma\s+solidity\s+([^;]+);`)
+)
+
+// VersionFromPragma returns the version constraint of the version pragma of
+// the given Solidity source, e.g. "^0.8.19" for "pragma solidity ^0.8.19;". If
+// the source contains multiple version pragmas, the returned constraint is
+// satisfied by the versions that satisfy all of them, e.g. "^0.8.0 <0.8.20" for
+// "pragma solidity ^0.8.0;" and "pragma solidity <0.8.20;".
+func VersionFromPragma(source string) (string, error) {
+	constraints, err := parsePragmas(source)
+	if err != nil {
+		return "", err
+	}
+	if len(constraints) == 0 {
+		return "", fmt.Errorf("solc: no version pragma found")
+	}
+	return intersectConstraints(constraints), nil
+}
+
+// parsePragmas returns the version constraints of the version pragmas of the
+// given Solidity source, or an error if one of them is invalid.
+func parsePragmas(source string) ([]string, error) {
+	source = commentRegexp.ReplaceAllString(source, "")
+
+	var constraints []string
+	for _, match := range pragmaRegexp.FindAllStringSubmatch(source, -1) {
+		constraint := strings.TrimSpace(match[1])
+		if _, err := version.ParseConstraint(constraint); err != nil {
+			return nil, fmt.Errorf("solc: %w", err)
+		}
+		constraints = append(constraints, constraint)
+	}
+	return constraints, nil
+}
+
+// intersectConstraints returns a constraint that is satisfied by the versions
+// that satisfy all given constraints. As constraints are alternatives of
+// version ranges separated by "||", each range of a constraint is combined
+// with each range of the other constraints.
+func intersectConstraints(constraints []string) string {
+	ranges := []string{""}
+	for _, constraint := range constraints {
+		var next []string
+		for _, r := range ranges {
+			for _, set := range strings.Split(constraint, "||") {
+				next = append(next, strings.TrimSpace(r+" "+strings.TrimSpace(set)))
+			}
+		}
+		ranges = next
+	}
+	return strings.Join(ranges, " || ")
+}
+
+// ResolvePragmas returns the highest version of [Versions] that satisfies the
+// version pragmas of all Solidity sources in the given directory. Sources
+// without version pragma are ignored. An error naming the source files is
+// returned if a version pragma is invalid.
+func ResolvePragmas(dir string) (Version, error) {
+	fsys := os.DirFS(dir)
+
+	var invalid []string
+	constraints := make(map[string]*version.Constraint) // keyed by source file
+	err := fs.WalkDir(fsys, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() || filepath.Ext(p) != ".sol" {
+			return nil
+		}
+
+		content, err := fs.ReadFile(fsys, p)
+		if err != nil {
+			return err
+		}
+		pragmas, err := parsePragmas(string(content))
+		if err != nil {
+			invalid = append(invalid, fmt.Sprintf("%s: %v", p, err))
+			return nil
+		}
+		if len(pragmas) == 0 {
+			// skip sources without version pragma
+			return nil
+		}
+		constraints[p], err = version.ParseConstraint(intersectConstraints(pragmas))
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	if len(invalid) > 0 {
+		return "", fmt.Errorf("solc: invalid version pragmas\n%s", strings.Join(invalid, "\n"))
+	}
+	return resolveConstraints(constraints, Versions)
+}
+
+// resolveConstraints returns the highest version of the given versions that
+// satisfies all given constraints.
+func resolveConstraints(constraints map[string]*version.Constraint, versions []Version) (Version, error) {
+	var best Version
+	for _, v := range versions {
+		ok := true
+		for _, c := range constraints {
+			if !c.Check(string(v)) {
+				ok = false
+				break
+			}
+		}
+		if ok && (best == "" || v.Cmp(best) > 0) {
+			best = v
+		}
+	}
+
+	if best == "" {
+		var lines []string
+		for _, file := range slices.Sorted(maps.Keys(constraints)) {
+			lines = append(lines, fmt.Sprintf("%s: %s", file, constraints[file]))
+		}
+		return "", fmt.Errorf("solc: no version satisfies all version pragmas\n%s", strings.Join(lines, "\n"))
+	}
+	return best, nil
+}
+
+// checkPragmas returns an error naming each source file and version pragma
+// that the given version does not satisfy. Each version pragma of a source must
+// be satisfied.
+// Sources given by URL are read relative to the given directory. Sources
+// without or with an unparsable version pragma are ignored and left to solc.
+func checkPragmas(dir string, srcMap map[string]src, v Version) error {
+	var lines []string
+	for _, name := range slices.Sorted(maps.Keys(srcMap)) {
+		source := readSource(dir, srcMap[name])
+		pragmas, err := parsePragmas(source.Content)
+		if err != nil {
+			continue
+		}
+		for _, pragma := range pragmas {
+			c, _ := version.ParseConstraint(pragma)
+			if !c.Check(string(v)) {
+				lines = append(lines, fmt.Sprintf("%s: pragma solidity %s", name, pragma))
+			}
+		}
+	}
+
+	if len(lines) > 0 {
+		return fmt.Errorf("solc: version %s does not satisfy %d version pragma(s)\n%s",
+			v, len(lines), strings.Join(lines, "\n"))
+	}
+	return nil
+}
+
+// NewFromPragma is like [New] but selects the solc version using
+// [ResolvePragmas] for the sources in the given directory.
+func NewFromPragma(dir, binPath string, opts ...CompilerOption) (*Compiler, error) {
+	v, err := ResolvePragmas(dir)
+	if err != nil {
+		return nil, err
+	}
+	return New(v, binPath, opts...)
+}
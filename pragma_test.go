@@ -0,0 +1,127 @@
+package solc
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/raszia/go-solc/internal/version"
+)
+
+func TestVersionFromPragma(t *testing.T) {
+	tests := []struct {
+		Source  string
+		Want    string
+		WantErr bool
+	}{
+		{Source: "pragma solidity ^0.8.19;", Want: "^0.8.19"},
+		{Source: "// SPDX-License-Identifier: MIT\npragma solidity >=0.7.0 <0.9.0;\ncontract A {}", Want: ">=0.7.0 <0.9.0"},
+		{Source: "// pragma solidity ^0.7.0;\npragma  solidity\t0.8.30 ;", Want: "0.8.30"},
+		{Source: "/* pragma solidity ^0.7.0; */ pragma solidity ^0.8.0;", Want: "^0.8.0"},
+		{Source: "pragma solidity ^0.8.0;\npragma solidity <0.8.20;", Want: "^0.8.0 <0.8.20"},
+		{Source: "pragma solidity 0.7.6 || ^0.8.0;\npragma solidity <0.8.20;", Want: "0.7.6 <0.8.20 || ^0.8.0 <0.8.20"},
+		{Source: "pragma solidity ^0.8.0;\npragma solidity foo;", WantErr: true},
+		{Source: "pragma abicoder v2; contract A {}", WantErr: true},
+		{Source: "pragma solidity foo;", WantErr: true},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := VersionFromPragma(test.Source)
+			if gotErr := err != nil; test.WantErr != gotErr {
+				t.Fatalf("want error %t, got %v", test.WantErr, err)
+			}
+			if test.Want != got {
+				t.Fatalf("want %q, got %q", test.Want, got)
+			}
+		})
+	}
+}
+
+func TestResolveConstraints(t *testing.T) {
+	versions := []Version{"0.7.6", "0.8.19", "0.8.30"}
+	mustParse := func(s string) *version.Constraint {
+		c, err := version.ParseConstraint(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return c
+	}
+
+	got, err := resolveConstraints(map[string]*version.Constraint{
+		"A.sol": mustParse("^0.8.0"),
+		"B.sol": mustParse("<0.8.20"),
+	}, versions)
+	if err != nil {
+		t.Fatalf("Failed to resolve: %v", err)
+	}
+	if want := Version("0.8.19"); want != got {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+
+	_, err = resolveConstraints(map[string]*version.Constraint{
+		"A.sol": mustParse("^0.8.0"),
+		"B.sol": mustParse("^0.7.0"),
+	}, versions)
+	if err == nil || !strings.Contains(err.Error(), "A.sol: ^0.8.0") || !strings.Contains(err.Error(), "B.sol: ^0.7.0") {
+		t.Fatalf("want error listing conflicting constraints, got %v", err)
+	}
+}
+
+func TestCheckPragmas(t *testing.T) {
+	srcMap := map[string]src{
+		"A.sol": {Content: "pragma solidity ^0.8.0;"},
+		"B.sol": {Content: "pragma solidity ^0.7.0;"},
+		"C.sol": {Content: "contract C {}"},
+		"D.sol": {Content: "pragma solidity foo;"},
+	}
+
+	if err := checkPragmas("", srcMap, "0.7.6"); err == nil || !strings.Contains(err.Error(), "A.sol: pragma solidity ^0.8.0") {
+		t.Fatalf("want error naming A.sol, got %v", err)
+	}
+	err := checkPragmas("", srcMap, "0.8.19")
+	if err == nil || !strings.Contains(err.Error(), "B.sol: pragma solidity ^0.7.0") || strings.Contains(err.Error(), "A.sol") {
+		t.Fatalf("want error naming B.sol only, got %v", err)
+	}
+
+	delete(srcMap, "B.sol")
+	if err := checkPragmas("", srcMap, "0.8.19"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+
+	// all version pragmas of a source must be satisfied
+	srcMap["E.sol"] = src{Content: "pragma solidity 0.7.6 || ^0.8.0;\npragma solidity <0.8.20;"}
+	if err := checkPragmas("", srcMap, "0.8.19"); err != nil {
+		t.Fatalf("want no error, got %v", err)
+	}
+	err = checkPragmas("", srcMap, "0.8.20")
+	if err == nil || !strings.Contains(err.Error(), "E.sol: pragma solidity <0.8.20") || strings.Contains(err.Error(), "||") {
+		t.Fatalf("want error naming the unsatisfied pragma of E.sol, got %v", err)
+	}
+}
+
+func TestResolvePragmasInvalid(t *testing.T) {
+	dir := t.TempDir()
+	createDummyContract(t, dir, "A", "pragma solidity ^0.8.0;")
+	createDummyContract(t, dir, "B", "pragma solidity foo;")
+
+	if _, err := ResolvePragmas(dir); err == nil || !strings.Contains(err.Error(), "B.sol") {
+		t.Fatalf("want error naming B.sol, got %v", err)
+	}
+}
+
+func TestCompilePragmaMismatch(t *testing.T) {
+	c := newFakeCompiler(t, `{"contracts": {"Test.sol": {"Test": {}}}}`)
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.7.0;")
+
+	_, err := c.Compile(srcDir, "Test", nil)
+	if err == nil || !strings.Contains(err.Error(), "Test.sol: pragma solidity ^0.7.0") {
+		t.Fatalf("want pragma error, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(filepath.Dir(c.solcAbsPath), "input.json")); err == nil {
+		t.Fatal("want solc not invoked")
+	}
+}
@@ -0,0 +1,201 @@
+package solc
+
+import (
+	"fmt"
+	"maps"
+	"slices"
+	"strings"
+)
+
+// ArtifactKind is a contract output of solc that can be selected in the output
+// selection.
+type ArtifactKind string
+
+// Artifact kinds.
+const (
+	ArtifactABI                 ArtifactKind = "abi"
+	ArtifactBytecode            ArtifactKind = "evm.bytecode.object"
+	ArtifactDeployedBytecode    ArtifactKind = "evm.deployedBytecode.object"
+	ArtifactImmutableReferences ArtifactKind = "evm.deployedBytecode.immutableReferences"
+	ArtifactMetadata            ArtifactKind = "metadata"
+	ArtifactMethodIdentifiers   ArtifactKind = "evm.methodIdentifiers"
+	ArtifactStorageLayout       ArtifactKind = "storageLayout"
+	ArtifactGasEstimates        ArtifactKind = "evm.gasEstimates"
+	ArtifactUserDoc             ArtifactKind = "userdoc"
+	ArtifactDevDoc              ArtifactKind = "devdoc"
+	ArtifactIR                  ArtifactKind = "ir"
+	ArtifactIROptimized         ArtifactKind = "irOptimized"
+	ArtifactAssembly            ArtifactKind = "evm.assembly"
+	ArtifactLegacyAssembly      ArtifactKind = "evm.legacyAssembly"
+	ArtifactAST                 ArtifactKind = "ast" // Per source file, see [Output.Sources].
+)
+
+// OutputArtifacts returns the output selection that selects the given
+// artifacts of all contracts in all source files.
+func OutputArtifacts(kinds ...ArtifactKind) map[string]map[string][]string {
+	sel := make(map[string][]string)
+	for _, kind := range kinds {
+		// the AST is selected per source file using the empty contract name
+		contract := "*"
+		if kind == ArtifactAST {
+			contract = ""
+		}
+		sel[contract] = append(sel[contract], string(kind))
+	}
+	return map[string]map[string][]string{"*": sel}
+}
+
+// OutputABI returns the output selection that selects the ABI of all
+// contracts.
+func OutputABI() map[string]map[string][]string {
+	return OutputArtifacts(ArtifactABI)
+}
+
+// OutputBytecode returns the output selection that selects the bytecode and
+// deployed bytecode of all contracts.
+func OutputBytecode() map[string]map[string][]string {
+	return OutputArtifacts(ArtifactBytecode, ArtifactDeployedBytecode)
+}
+
+// SelectionBuilder builds output selections that select different artifacts
+// for specific source files or contracts. solc combines the artifacts selected
+// for a contract with the artifacts selected by the wildcard selections of its
+// source file and of all source files.
+//
+// Example selecting the ABI of all contracts and the bytecode of Token:
+//
+//	sel := solc.NewSelectionBuilder(solc.ArtifactABI).
+//		Contract("Token.sol", "Token", solc.ArtifactBytecode).
+//		Build()
+type SelectionBuilder struct {
+	sel map[string]map[string][]string
+}
+
+// NewSelectionBuilder returns a [SelectionBuilder] that selects the given
+// artifacts of all contracts in all source files.
+func NewSelectionBuilder(kinds ...ArtifactKind) *SelectionBuilder {
+	return &SelectionBuilder{sel: OutputArtifacts(kinds...)}
+}
+
+// File selects the given artifacts of all contracts in the given source file.
+func (b *SelectionBuilder) File(file string, kinds ...ArtifactKind) *SelectionBuilder {
+	for _, kind := range kinds {
+		contract := "*"
+		if kind == ArtifactAST {
+			contract = ""
+		}
+		b.add(file, contract, kind)
+	}
+	return b
+}
+
+// Contract selects the given artifacts of the contract with the given name in
+// the given source file.
+func (b *SelectionBuilder) Contract(file, contract string, kinds ...ArtifactKind) *SelectionBuilder {
+	for _, kind := range kinds {
+		b.add(file, contract, kind)
+	}
+	return b
+}
+
+func (b *SelectionBuilder) add(file, contract string, kind ArtifactKind) {
+	if b.sel[file] == nil {
+		b.sel[file] = make(map[string][]string)
+	}
+	if !slices.Contains(b.sel[file][contract], string(kind)) {
+		b.sel[file][contract] = append(b.sel[file][contract], string(kind))
+	}
+}
+
+// Build returns the output selection.
+func (b *SelectionBuilder) Build() map[string]map[string][]string {
+	sel := make(map[string]map[string][]string, len(b.sel))
+	for file, contracts := range b.sel {
+		sel[file] = make(map[string][]string, len(contracts))
+		for contract, kinds := range contracts {
+			sel[file][contract] = slices.Clone(kinds)
+		}
+	}
+	return sel
+}
+
+// AllStandardOutputs returns the output selection that selects the artifacts
+// of a full build of all contracts: the ABI, bytecode, deployed bytecode,
+// metadata, method identifiers, storage layout and gas estimates. A new map is
+// returned on each call, so artifacts may be removed from it or added to it.
+func AllStandardOutputs() map[string]map[string][]string {
+	return OutputArtifacts(
+		ArtifactABI,
+		ArtifactBytecode,
+		ArtifactDeployedBytecode,
+		ArtifactMetadata,
+		ArtifactMethodIdentifiers,
+		ArtifactStorageLayout,
+		ArtifactGasEstimates,
+	)
+}
+
+// outputs are the outputs solc accepts in an output selection, including
+// prefixes of nested outputs, which select all outputs they contain.
+var outputs = map[string]struct{}{
+	"*":                      {},
+	"ast":                    {},
+	"legacyAST":              {},
+	"abi":                    {},
+	"devdoc":                 {},
+	"userdoc":                {},
+	"metadata":               {},
+	"ir":                     {},
+	"irAst":                  {},
+	"irOptimized":            {},
+	"irOptimizedAst":         {},
+	"storageLayout":          {},
+	"transientStorageLayout": {},
+	"yulCFGJson":             {},
+
+	"evm":                   {},
+	"evm.assembly":          {},
+	"evm.legacyAssembly":    {},
+	"evm.methodIdentifiers": {},
+	"evm.gasEstimates":      {},
+
+	"evm.bytecode":                   {},
+	"evm.bytecode.object":            {},
+	"evm.bytecode.opcodes":           {},
+	"evm.bytecode.sourceMap":         {},
+	"evm.bytecode.linkReferences":    {},
+	"evm.bytecode.functionDebugData": {},
+	"evm.bytecode.generatedSources":  {},
+
+	"evm.deployedBytecode":                     {},
+	"evm.deployedBytecode.object":              {},
+	"evm.deployedBytecode.opcodes":             {},
+	"evm.deployedBytecode.sourceMap":           {},
+	"evm.deployedBytecode.linkReferences":      {},
+	"evm.deployedBytecode.functionDebugData":   {},
+	"evm.deployedBytecode.generatedSources":    {},
+	"evm.deployedBytecode.immutableReferences": {},
+
+	"ewasm":      {},
+	"ewasm.wast": {},
+	"ewasm.wasm": {},
+}
+
+// validateOutputSelection checks that the given output selection only selects
+// outputs solc accepts, such that typos don't silently select nothing.
+func validateOutputSelection(sel map[string]map[string][]string) error {
+	var unknown []string
+	for _, file := range slices.Sorted(maps.Keys(sel)) {
+		for _, contract := range slices.Sorted(maps.Keys(sel[file])) {
+			for _, output := range sel[file][contract] {
+				if _, ok := outputs[output]; !ok {
+					unknown = append(unknown, fmt.Sprintf("%s:%s: %q", file, contract, output))
+				}
+			}
+		}
+	}
+	if len(unknown) > 0 {
+		return fmt.Errorf("solc: unknown outputs in output selection\n%s", strings.Join(unknown, "\n"))
+	}
+	return nil
+}
@@ -0,0 +1,105 @@
+package solc
+
+import (
+	"encoding/json"
+	"strconv"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestOutputArtifacts(t *testing.T) {
+	tests := []struct {
+		Sel  map[string]map[string][]string
+		Want map[string]map[string][]string
+	}{
+		{
+			Sel:  OutputABI(),
+			Want: map[string]map[string][]string{"*": {"*": {"abi"}}},
+		},
+		{
+			Sel:  OutputBytecode(),
+			Want: map[string]map[string][]string{"*": {"*": {"evm.bytecode.object", "evm.deployedBytecode.object"}}},
+		},
+		{
+			Sel: AllStandardOutputs(),
+			Want: map[string]map[string][]string{"*": {"*": {
+				"abi", "evm.bytecode.object", "evm.deployedBytecode.object", "metadata",
+				"evm.methodIdentifiers", "storageLayout", "evm.gasEstimates",
+			}}},
+		},
+		{
+			Sel: OutputArtifacts(ArtifactABI, ArtifactAST, ArtifactStorageLayout),
+			Want: map[string]map[string][]string{"*": {
+				"*": {"abi", "storageLayout"},
+				"":  {"ast"},
+			}},
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if diff := cmp.Diff(test.Want, test.Sel); diff != "" {
+				t.Fatalf("(-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestSelectionBuilder(t *testing.T) {
+	sel := NewSelectionBuilder(ArtifactABI).
+		File("Lib.sol", ArtifactAST, ArtifactMetadata).
+		Contract("Token.sol", "Token", ArtifactBytecode, ArtifactDeployedBytecode, ArtifactBytecode).
+		Build()
+
+	data, err := json.Marshal(sel)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"*":{"*":["abi"]},"Lib.sol":{"":["ast"],"*":["metadata"]},` +
+		`"Token.sol":{"Token":["evm.bytecode.object","evm.deployedBytecode.object"]}}`
+	if got := string(data); want != got {
+		t.Fatalf("want %s, got %s", want, got)
+	}
+}
+
+func TestAllStandardOutputsCopy(t *testing.T) {
+	sel := AllStandardOutputs()
+	sel["*"]["*"] = sel["*"]["*"][:1]
+
+	if got := len(AllStandardOutputs()["*"]["*"]); got != 7 {
+		t.Fatalf("want 7 artifacts, got %d", got)
+	}
+}
+
+func TestValidateOutputSelection(t *testing.T) {
+	tests := []struct {
+		Sel     map[string]map[string][]string
+		WantErr string
+	}{
+		{Sel: AllStandardOutputs()},
+		{Sel: DefaultOutputSelection},
+		{Sel: map[string]map[string][]string{"*": {"": {"ast"}, "*": {"evm.bytecode", "*"}}}},
+		{
+			Sel:     map[string]map[string][]string{"*": {"*": {"abi", "evm.bytcode.object"}}, "A.sol": {"A": {"metdata"}}},
+			WantErr: "solc: unknown outputs in output selection\n*:*: \"evm.bytcode.object\"\nA.sol:A: \"metdata\"",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			err := validateOutputSelection(test.Sel)
+			if test.WantErr == "" && err != nil {
+				t.Fatalf("want no error, got %v", err)
+			}
+			if test.WantErr != "" && (err == nil || err.Error() != test.WantErr) {
+				t.Fatalf("want error %q, got %v", test.WantErr, err)
+			}
+		})
+	}
+
+	c := &Compiler{version: VersionLatest}
+	if _, err := c.buildSettings(map[string]map[string][]string{"*": {"*": {"abii"}}}, nil); err == nil {
+		t.Fatal("want error for unknown output")
+	}
+}
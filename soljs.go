@@ -0,0 +1,170 @@
+package solc
+
+import (
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// SolJSRunner runs the soljson.js build of solc at the given path with the
+// given standard JSON input and returns the standard JSON output. See
+// [WithSolJSRunner].
+//
+// All sources of the input are passed by content. Imports of files that are
+// not part of the input must be resolved by the runner, e.g. using an import
+// callback.
+type SolJSRunner func(ctx context.Context, soljsonPath string, input []byte) ([]byte, error)
+
+// soljsonName returns the file name of the installed soljson.js build of the
+// given version.
+func soljsonName(version Version) string {
+	return "soljson_v" + version.String() + ".js"
+}
+
+// isSolJS reports whether the compiler uses a soljson.js build instead of a
+// native solc binary.
+func (c *Compiler) isSolJS() bool {
+	return c.solJSRunner != nil && filepath.Base(c.solcAbsPath) == soljsonName(c.version)
+}
+
+// checkSolJS checks for the existence of the soljson.js build of the
+// compiler's version and attempts to download it if it does not exist yet,
+// unless the compiler is in offline mode. If hasPin is set, the checksum of the
+// build must match the pinned checksum.
+func (c *Compiler) checkSolJS(pinned [32]byte, hasPin bool) (string, error) {
+	path := filepath.Join(c.binPath, soljsonName(c.version))
+	if fileExists(path) {
+		if hasPin {
+			if err := verifyInstalledChecksum(c.version, path, pinned); err != nil {
+				return "", err
+			}
+		}
+		return path, nil
+	}
+	if c.offline {
+		return "", fmt.Errorf("solc: version %q is not installed in %s (offline mode)", c.version, c.binPath)
+	}
+
+	baseURL, err := c.soljsonBaseURL()
+	if err != nil {
+		return "", err
+	}
+
+	var v solcVersion
+	listURL := baseURL + "list.json"
+	err = c.withRetries(listURL, func(int) (err error) {
+		v, err = fetchSolJSBuild(c.httpClient(), listURL, c.version)
+		return err
+	})
+	if err != nil {
+		return "", err
+	}
+	if hasPin {
+		v.Sha256 = pinned
+	}
+	if err := makeBinDir(c.binPath); err != nil {
+		return "", err
+	}
+
+	start := time.Now()
+	_, err, _ = dg.Do(path, func() (any, error) {
+		if fileExists(path) {
+			return nil, nil
+		}
+		c.log().Info("downloading soljson.js", "version", c.version, "url", baseURL+v.Path)
+		err := c.withRetries(baseURL+v.Path, func(int) error {
+			return c.download(baseURL, path, v)
+		})
+		if err != nil {
+			return nil, fmt.Errorf("solc: failed to download soljson.js %q: %w", c.version, err)
+		}
+		return nil, nil
+	})
+	if err != nil {
+		return "", err
+	}
+	c.downloadDuration = time.Since(start)
+	return path, nil
+}
+
+// soljsonBaseURL returns the base URL to download the version list and
+// soljson.js builds from, i.e. the "bin" directory of solc-bin. If
+// [WithDownloadBaseURL] is set, it is the sibling "bin" directory of the
+// configured platform directory of the mirror.
+func (c *Compiler) soljsonBaseURL() (string, error) {
+	if c.baseURL == "" {
+		return solcBinURL + "bin/", nil
+	}
+	base, err := url.Parse(c.baseURL)
+	if err != nil {
+		return "", fmt.Errorf("solc: invalid download base URL %q: %w", c.baseURL, err)
+	}
+	return base.JoinPath("..", "bin").String() + "/", nil
+}
+
+// fetchSolJSBuild returns the path and checksum of the soljson.js build of the
+// given version from the version list at the given URL.
+func fetchSolJSBuild(client *http.Client, url string, version Version) (solcVersion, error) {
+	resp, err := client.Get(url)
+	if err != nil {
+		return solcVersion{}, fmt.Errorf("solc: failed to fetch version list: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return solcVersion{}, fmt.Errorf("solc: failed to fetch version list: %w", newStatusError(resp))
+	}
+
+	var list struct {
+		Builds []struct {
+			Path       string `json:"path"`
+			Version    string `json:"version"`
+			Prerelease string `json:"prerelease"`
+			Sha256     string `json:"sha256"`
+		} `json:"builds"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&list); err != nil {
+		return solcVersion{}, fmt.Errorf("solc: failed to decode version list: %w", err)
+	}
+
+	for _, build := range list.Builds {
+		if build.Version != version.String() || build.Prerelease != "" {
+			continue
+		}
+		var v solcVersion
+		sum, err := hex.DecodeString(strings.TrimPrefix(build.Sha256, "0x"))
+		if err != nil || len(sum) != len(v.Sha256) {
+			return solcVersion{}, fmt.Errorf("solc: invalid checksum of soljson.js %q", version)
+		}
+		v.Path = build.Path
+		copy(v.Sha256[:], sum)
+		return v, nil
+	}
+	return solcVersion{}, fmt.Errorf("solc: unknown version %q", version)
+}
+
+// runSolJS runs the soljson.js build of the compiler with the given standard
+// JSON input. Sources given by URL are read relative to the given directory and
+// passed by content.
+func (c *Compiler) runSolJS(ctx context.Context, dir string, inputJSON []byte) ([]byte, error) {
+	inputJSON, err := inlineSources(dir, inputJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	c.log().Debug("running soljson.js", "path", c.solcAbsPath, "dir", dir)
+	out, err := c.solJSRunner(ctx, c.solcAbsPath, inputJSON)
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("solc %s: %w", c.version, context.Cause(ctx))
+		}
+		return nil, err
+	}
+	return out, nil
+}
@@ -0,0 +1,86 @@
+package solc
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestSolJSFallback(t *testing.T) {
+	soljson := []byte("// soljson.js")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.URL.Path {
+		case "/mirror/bin/list.json":
+			fmt.Fprintf(w, `{"builds": [{"path": "soljson-v0.8.30+commit.73712a01.js", "version": "0.8.30", "sha256": "0x%x"}]}`,
+				sha256.Sum256(soljson))
+		case "/mirror/bin/soljson-v0.8.30+commit.73712a01.js":
+			w.Write(soljson)
+		default:
+			http.NotFound(w, r)
+		}
+	}))
+	defer srv.Close()
+
+	defer func(os, arch string) { goos, goarch = os, arch }(goos, goarch)
+	goos, goarch = "linux", "arm64"
+	mirror := WithDownloadBaseURL(srv.URL + "/mirror/linux-arm64")
+	cacheMux.Lock()
+	clear(cache)
+	cacheMux.Unlock()
+
+	var gotPath string
+	var gotInput map[string]any
+	runner := func(ctx context.Context, soljsonPath string, input []byte) ([]byte, error) {
+		gotPath = soljsonPath
+		if err := json.Unmarshal(input, &gotInput); err != nil {
+			return nil, err
+		}
+		return []byte(`{"contracts": {"Test.sol": {"Test": {}}}}`), nil
+	}
+
+	binPath := t.TempDir()
+	if _, err := New("0.8.30", binPath, mirror); err == nil {
+		t.Fatal("want unsupported platform error without runner")
+	}
+	c, err := New("0.8.30", binPath, WithSolJSRunner(runner), mirror)
+	if err != nil {
+		t.Fatalf("Failed to create compiler: %v", err)
+	}
+	want := filepath.Join(binPath, "soljson_v0.8.30.js")
+	if got, err := os.ReadFile(want); err != nil || string(got) != string(soljson) {
+		t.Fatalf("want soljson.js installed, got %q, %v", got, err)
+	}
+
+	srcDir := t.TempDir()
+	createDummyContract(t, srcDir, "Test", "pragma solidity ^0.8.0;")
+	contracts, err := c.Compile(srcDir, "Test", nil)
+	if err != nil {
+		t.Fatalf("Compile failed: %v", err)
+	}
+	if len(contracts["Test.sol"]) != 1 {
+		t.Fatalf("want contract Test, got %v", contracts)
+	}
+	if gotPath != want {
+		t.Fatalf("want soljson.js path %q, got %q", want, gotPath)
+	}
+	source := gotInput["sources"].(map[string]any)["Test.sol"].(map[string]any)
+	if source["content"] != "pragma solidity ^0.8.0;" {
+		t.Fatalf("want source passed by content, got %v", source)
+	}
+
+	// the installed soljson.js is used in offline mode
+	srv.Close()
+	if _, err := New("0.8.30", binPath, WithSolJSRunner(runner), WithOffline()); err != nil {
+		t.Fatalf("Failed to create offline compiler: %v", err)
+	}
+	if _, err := New("0.8.30", t.TempDir(), WithSolJSRunner(runner), WithOffline()); err == nil || !strings.Contains(err.Error(), "offline mode") {
+		t.Fatalf("want offline mode error without installed soljson.js, got %v", err)
+	}
+}
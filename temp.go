@@ -0,0 +1,51 @@
+package solc
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+)
+
+// makeTempDir creates a new temporary directory in "<binPath>/tmp/", or in the
+// default directory for temporary files if the compiler has no bin directory.
+// The caller must remove the directory once it is no longer needed.
+func (c *Compiler) makeTempDir() (string, error) {
+	if c.binPath == "" {
+		return os.MkdirTemp("", "solc")
+	}
+
+	tmpDir := filepath.Join(c.binPath, "tmp")
+	if err := os.MkdirAll(tmpDir, perm); err != nil {
+		return "", err
+	}
+	return os.MkdirTemp(tmpDir, "solc")
+}
+
+// CleanTemp removes temporary files and directories in the bin directory that
+// were left behind by crashed runs: temporary directories in "<binPath>/tmp/"
+// and partial downloads and cache files.
+//
+// CleanTemp must not be called while other processes use the same bin
+// directory, as it also removes their temporary files.
+func (c *Compiler) CleanTemp() error {
+	if c.binPath == "" {
+		return nil
+	}
+
+	var errs []error
+	if err := os.RemoveAll(filepath.Join(c.binPath, "tmp")); err != nil {
+		errs = append(errs, err)
+	}
+	for _, pattern := range []string{
+		filepath.Join(c.binPath, "*.tmp"),          // partial downloads
+		filepath.Join(c.binPath, "cache", "*.tmp"), // partial cache files
+	} {
+		paths, _ := filepath.Glob(pattern)
+		for _, path := range paths {
+			if err := os.Remove(path); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,66 @@
+package solc
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+)
+
+func TestMakeTempDir(t *testing.T) {
+	c := &Compiler{binPath: t.TempDir()}
+
+	dir, err := c.makeTempDir()
+	if err != nil {
+		t.Fatalf("Failed to make temp dir: %v", err)
+	}
+	if want := filepath.Join(c.binPath, "tmp"); filepath.Dir(dir) != want {
+		t.Fatalf("want temp dir in %s, got %s", want, dir)
+	}
+}
+
+func TestCleanTemp(t *testing.T) {
+	c := &Compiler{binPath: t.TempDir()}
+
+	// simulate leftovers of crashed runs
+	if _, err := c.makeTempDir(); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.MkdirAll(filepath.Join(c.binPath, "cache"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	for _, name := range []string{"solc_v0.8.30.123.tmp", "cache/key.json.456.tmp"} {
+		if err := os.WriteFile(filepath.Join(c.binPath, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// files that must be kept
+	keep := []string{"solc_v0.8.30", "cache/key.json"}
+	for _, name := range keep {
+		if err := os.WriteFile(filepath.Join(c.binPath, name), nil, 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	if err := c.CleanTemp(); err != nil {
+		t.Fatalf("Failed to clean temp: %v", err)
+	}
+
+	var got []string
+	filepath.WalkDir(c.binPath, func(path string, d os.DirEntry, err error) error {
+		if err == nil && !d.IsDir() {
+			rel, _ := filepath.Rel(c.binPath, path)
+			got = append(got, filepath.ToSlash(rel))
+		}
+		return err
+	})
+	want := []string{"cache/key.json", "solc_v0.8.30"}
+	if diff := cmp.Diff(want, got); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+	if _, err := os.Stat(filepath.Join(c.binPath, "tmp")); !os.IsNotExist(err) {
+		t.Fatalf("want tmp dir removed, got %v", err)
+	}
+}
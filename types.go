@@ -1,10 +1,19 @@
 package solc
 
 import (
+	"bytes"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
+	"maps"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
 )
 
 // Contract represents a compiled contract.
@@ -17,6 +26,19 @@ const (
 	langYul      lang = "Yul"
 )
 
+// ext returns the file extension of source files of the language.
+func (l lang) ext() string {
+	if l == langYul {
+		return ".yul"
+	}
+	return ".sol"
+}
+
+// isValid returns true if l is a language supported by this package.
+func (l lang) isValid() bool {
+	return l == langSolidity || l == langYul
+}
+
 // EVMVersion represents the EVM version to compile for.
 type EVMVersion string
 
@@ -31,14 +53,63 @@ const (
 	EVMVersionPetersburg EVMVersion = "petersburg"
 	EVMVersionByzantium  EVMVersion = "byzantium"
 	EVMVersionOsaka      EVMVersion = "osaka"
+
+	EVMVersionConstantinople   EVMVersion = "constantinople"
+	EVMVersionSpuriousDragon   EVMVersion = "spuriousDragon"
+	EVMVersionTangerineWhistle EVMVersion = "tangerineWhistle"
+	EVMVersionHomestead        EVMVersion = "homestead"
 )
 
+// evmVersions maps the EVM versions known to solc to the first solc version
+// supporting them. EVM versions supported by all solc versions of this package
+// map to "0.5.0".
+var evmVersions = map[EVMVersion]Version{
+	EVMVersionOsaka:            "0.8.29",
+	EVMVersionPrague:           "0.8.27",
+	EVMVersionCancun:           "0.8.24",
+	EVMVersionShanghai:         "0.8.20",
+	EVMVersionParis:            "0.8.18",
+	EVMVersionLondon:           "0.8.7",
+	EVMVersionBerlin:           "0.8.5",
+	EVMVersionIstanbul:         "0.5.13",
+	EVMVersionPetersburg:       "0.5.5",
+	EVMVersionConstantinople:   "0.5.0",
+	EVMVersionByzantium:        "0.5.0",
+	EVMVersionSpuriousDragon:   "0.5.0",
+	EVMVersionTangerineWhistle: "0.5.0",
+	EVMVersionHomestead:        "0.5.0",
+}
+
+// isValid returns true if v is an EVM version known to solc.
+func (v EVMVersion) isValid() bool {
+	_, ok := evmVersions[v]
+	return ok
+}
+
 type input struct {
 	Lang     lang           `json:"language"`
 	Sources  map[string]src `json:"sources"`
 	Settings *Settings      `json:"settings"`
 }
 
+// marshal returns the standard JSON input, transformed by the input
+// transformer of the settings if set.
+func (in *input) marshal() ([]byte, error) {
+	inputJSON, err := json.Marshal(in)
+	if err != nil || in.Settings == nil || in.Settings.transform == nil {
+		return inputJSON, err
+	}
+
+	var m map[string]any
+	if err := json.Unmarshal(inputJSON, &m); err != nil {
+		return nil, err
+	}
+	if inputJSON, err = json.Marshal(in.Settings.transform(m)); err != nil {
+		return nil, fmt.Errorf("solc: failed to encode transformed input: %w", err)
+	}
+	return inputJSON, nil
+}
+
 type src struct {
 	Keccak256 string   `json:"keccak256,omitempty"`
 	Content   string   `json:"content,omitempty"`
@@ -47,88 +118,650 @@ type src struct {
 
 // Settings for the compilation.
 type Settings struct {
-	lang            lang                           `json:"-"`
-	Remappings      []string                       `json:"remappings,omitempty"`
-	Optimizer       *Optimizer                     `json:"optimizer"`
-	ViaIR           bool                           `json:"viaIR,omitempty"`
-	EVMVersion      EVMVersion                     `json:"evmVersion"`
-	OutputSelection map[string]map[string][]string `json:"outputSelection"`
+	lang                lang                                `json:"-"`
+	strictWarnings      bool                                `json:"-"`
+	basePath            string                              `json:"-"`
+	includePaths        []string                            `json:"-"`
+	noCache             bool                                `json:"-"`
+	restrictPaths       bool                                `json:"-"`
+	allowedPaths        []string                            `json:"-"`
+	noFilesystem        bool                                `json:"-"`
+	timeout             time.Duration                       `json:"-"`
+	extraArgs           []string                            `json:"-"`
+	returnSources       bool                                `json:"-"`
+	checkImports        bool                                `json:"-"`
+	transform           func(map[string]any) map[string]any `json:"-"`
+	Remappings          []string                            `json:"remappings,omitempty"`
+	Optimizer           *Optimizer                          `json:"optimizer"`
+	ParserErrorRecovery bool                                `json:"parserErrorRecovery,omitempty"`
+	StopAfter           string                              `json:"stopAfter,omitempty"`
+	ViaIR               bool                                `json:"viaIR,omitempty"`
+	EVMVersion          EVMVersion                          `json:"evmVersion"`
+	Libraries           map[string]map[string]string        `json:"libraries,omitempty"`
+	Metadata            *Metadata                           `json:"metadata,omitempty"`
+	OutputSelection     map[string]map[string][]string      `json:"outputSelection"`
+}
+
+// Metadata configures the contract metadata.
+type Metadata struct {
+	UseLiteralContent bool         `json:"useLiteralContent,omitempty"`
+	BytecodeHash      BytecodeHash `json:"bytecodeHash,omitempty"`
+}
+
+// BytecodeHash is the hash of the metadata that is appended to the bytecode.
+type BytecodeHash string
+
+const (
+	BytecodeHashIPFS  BytecodeHash = "ipfs"
+	BytecodeHashBzzr1 BytecodeHash = "bzzr1"
+	BytecodeHashNone  BytecodeHash = "none"
+)
+
+// isValid returns true if h is a bytecode hash known to solc.
+func (h BytecodeHash) isValid() bool {
+	switch h {
+	case BytecodeHashIPFS, BytecodeHashBzzr1, BytecodeHashNone:
+		return true
+	}
+	return false
 }
 
 type Optimizer struct {
-	Enabled bool   `json:"enabled"`
-	Runs    uint64 `json:"runs"`
+	Enabled bool              `json:"enabled"`
+	Runs    uint64            `json:"runs"`
+	Details *OptimizerDetails `json:"details,omitempty"`
+}
+
+// OptimizerDetails switches individual optimizer components on or off. Only
+// components that are set (non-nil) are passed to solc, all others keep solc's
+// default. Details are passed as given even if the optimizer is disabled, e.g.
+// to explicitly disable the Yul optimizer, which solc otherwise runs in a
+// reduced form.
+type OptimizerDetails struct {
+	Peephole          *bool       `json:"peephole,omitempty"`
+	Inliner           *bool       `json:"inliner,omitempty"`
+	JumpdestRemover   *bool       `json:"jumpdestRemover,omitempty"`
+	OrderLiterals     *bool       `json:"orderLiterals,omitempty"`
+	Deduplicate       *bool       `json:"deduplicate,omitempty"`
+	CSE               *bool       `json:"cse,omitempty"`
+	ConstantOptimizer *bool       `json:"constantOptimizer,omitempty"`
+	Yul               *bool       `json:"yul,omitempty"`
+	YulDetails        *YulDetails `json:"yulDetails,omitempty"`
+}
+
+// YulDetails tunes the Yul optimizer.
+type YulDetails struct {
+	StackAllocation *bool  `json:"stackAllocation,omitempty"`
+	OptimizerSteps  string `json:"optimizerSteps,omitempty"`
+}
+
+// Output is the standard JSON output of solc.
+type Output struct {
+	Errors    []Diagnostic                   `json:"errors"`    // Errors, warnings and infos.
+	Sources   map[string]SourceOutput        `json:"sources"`   // Keyed by source file.
+	Contracts map[string]map[string]Contract `json:"contracts"` // Keyed by source file and contract name.
+
+	// Raw is the complete standard JSON output of solc, including fields that
+	// are not modeled by this package.
+	Raw json.RawMessage `json:"-"`
+
+	// FromCache reports whether the output was served from the in-memory
+	// cache or the compiler's [Cache] instead of running solc.
+	FromCache bool `json:"-"`
+
+	// Timing are the durations of the steps of the compilation.
+	Timing Timing `json:"-"`
+
+	// Invocation is the solc invocation of the compilation.
+	Invocation Invocation `json:"-"`
 }
 
-type output struct {
-	Errors    []error_                       `json:"errors"`
-	Sources   map[string]srcOut              `json:"sources"`
-	Contracts map[string]map[string]Contract `json:"contracts"`
+// Timing are the durations of the steps of a compilation.
+type Timing struct {
+	DownloadDuration time.Duration // Download of solc by New, only set for the first output of the compiler
+	SolcDuration     time.Duration // Run of solc, zero if the output was cached
+	ParseDuration    time.Duration // Decoding of the standard JSON output
 }
 
-func (o *output) Err() error {
-	var fmtMsgs []string
-	for _, err := range o.Errors {
-		if strings.EqualFold(err.Severity, "error") {
-			fmtMsgs = append(fmtMsgs, err.FormattedMessage)
+// Meta is metadata of a compilation, see [Compiler.CompileWithMeta].
+type Meta struct {
+	FromCache  bool       // Output was served from a cache instead of running solc
+	Timing     Timing     // Durations of the steps of the compilation
+	Invocation Invocation // solc invocation of the compilation
+}
+
+// setSourceContents sets the content of all sources of the output to the
+// given contents keyed by source unit name, or returns an error if the content
+// of a source is missing.
+func (o *Output) setSourceContents(contents map[string]string) error {
+	for name, source := range o.Sources {
+		content, ok := contents[name]
+		if !ok {
+			return fmt.Errorf("solc: content of source %q not found", name)
 		}
+		source.Content = content
+		o.Sources[name] = source
 	}
+	return nil
+}
 
-	if len(fmtMsgs) == 0 {
+// normalizePaths replaces absolute source file paths in the output, e.g. of
+// sources imported via a remapping to an absolute path, by paths relative to
+// the given root directory, such that the output does not depend on where it
+// was compiled. Paths are normalized if they are inside the root directory and
+// the relative path is not a source file of the output yet. All other paths
+// are kept as reported by solc.
+func (o *Output) normalizePaths(root string) {
+	rel := func(file string) (string, bool) {
+		if !filepath.IsAbs(filepath.FromSlash(file)) {
+			return "", false
+		}
+		r, err := filepath.Rel(root, filepath.FromSlash(file))
+		if err != nil || r == ".." || strings.HasPrefix(r, ".."+string(filepath.Separator)) {
+			return "", false
+		}
+		r = filepath.ToSlash(r)
+		_, srcExists := o.Sources[r]
+		_, contractExists := o.Contracts[r]
+		return r, !srcExists && !contractExists
+	}
+
+	renamed := make(map[string]string)
+	for file := range o.Sources {
+		if r, ok := rel(file); ok {
+			renamed[file] = r
+		}
+	}
+	for file := range o.Contracts {
+		if r, ok := rel(file); ok {
+			renamed[file] = r
+		}
+	}
+
+	for file, r := range renamed {
+		if src, ok := o.Sources[file]; ok {
+			delete(o.Sources, file)
+			o.Sources[r] = src
+		}
+		if contracts, ok := o.Contracts[file]; ok {
+			delete(o.Contracts, file)
+			o.Contracts[r] = contracts
+		}
+	}
+	for i, diag := range o.Errors {
+		if r, ok := renamed[diag.SourceLocation.File]; ok {
+			o.Errors[i].SourceLocation.File = r
+		}
+	}
+}
+
+// result returns the compiled contracts with the given name (or all contracts
+// if the name is empty) and all diagnostics of the output, or an error if the
+// compilation failed or no contract has the given name. With parser error
+// recovery, the contracts of the partial output are returned together with the
+// error.
+func (o *Output) result(s *Settings, contract string) (map[string]map[string]Contract, []Diagnostic, error) {
+	err := o.err(s.strictWarnings)
+	if err != nil && !s.ParserErrorRecovery {
+		return nil, o.Errors, err
+	}
+	if contract == "" {
+		return o.Contracts, o.Errors, err
+	}
+
+	contracts := make(map[string]map[string]Contract)
+	for file, fileContracts := range o.Contracts {
+		if c, ok := fileContracts[contract]; ok {
+			contracts[file] = map[string]Contract{contract: c}
+		}
+	}
+	if len(contracts) == 0 && err == nil {
+		return nil, o.Errors, fmt.Errorf("solc: contract %q not found", contract)
+	}
+	return contracts, o.Errors, err
+}
+
+// err returns an error if solc reported any errors. If strictWarnings is set,
+// warnings are treated as errors.
+func (o *Output) err(strictWarnings bool) error {
+	var diags []Diagnostic
+	for _, diag := range o.Errors {
+		if strings.EqualFold(diag.Severity, "error") ||
+			strictWarnings && strings.EqualFold(diag.Severity, "warning") {
+			diags = append(diags, diag)
+		}
+	}
+
+	if len(diags) == 0 {
 		return nil
 	}
-	return fmt.Errorf("solc: compilation failed\n%s", strings.Join(fmtMsgs, "\n"))
+	return &CompileError{Diagnostics: diags, All: o.Errors}
 }
 
-type error_ struct {
-	SourceLocation   sourceLocation `json:"sourceLocation"`
-	Type             string         `json:"type"`
-	Component        string         `json:"component"`
-	Severity         string         `json:"severity"`
+// CompileError is returned if solc reported errors, or warnings if
+// [WithStrictWarnings] is set.
+type CompileError struct {
+	Diagnostics []Diagnostic // Diagnostics that caused the compilation to fail.
+	All         []Diagnostic // All diagnostics reported by solc, including warnings and infos.
+}
+
+// Errors returns all diagnostics with severity "error".
+func (e *CompileError) Errors() []Diagnostic { return e.filter("error") }
+
+// Warnings returns all diagnostics with severity "warning".
+func (e *CompileError) Warnings() []Diagnostic { return e.filter("warning") }
+
+// FirstError returns the first diagnostic with severity "error", or nil if
+// there is none, e.g. if the compilation failed due to [WithStrictWarnings].
+func (e *CompileError) FirstError() *Diagnostic {
+	errs := e.Errors()
+	if len(errs) == 0 {
+		return nil
+	}
+	return &errs[0]
+}
+
+// filter returns all diagnostics with the given severity.
+func (e *CompileError) filter(severity string) []Diagnostic {
+	all := e.All
+	if all == nil {
+		all = e.Diagnostics
+	}
+
+	var diags []Diagnostic
+	for _, diag := range all {
+		if strings.EqualFold(diag.Severity, severity) {
+			diags = append(diags, diag)
+		}
+	}
+	return diags
+}
+
+func (e *CompileError) Error() string {
+	msgs := make([]string, len(e.Diagnostics))
+	for i, diag := range e.Diagnostics {
+		msgs[i] = diag.String()
+	}
+	return "solc: compilation failed\n" + strings.Join(msgs, "\n")
+}
+
+// Diagnostic is an error, warning or info message reported by solc.
+type Diagnostic struct {
+	SourceLocation   SourceLocation `json:"sourceLocation"`
+	Type             string         `json:"type"`      // E.g. "TypeError" or "Warning".
+	Component        string         `json:"component"` // E.g. "general".
+	Severity         string         `json:"severity"`  // "error", "warning" or "info".
+	ErrorCode        string         `json:"errorCode"`
 	Message          string         `json:"message"`
 	FormattedMessage string         `json:"formattedMessage"`
 }
 
-type sourceLocation struct {
+// String returns the formatted message of the diagnostic as rendered by solc,
+// or a short form "file:start: Type: message" if solc did not format it.
+func (d Diagnostic) String() string {
+	if d.FormattedMessage != "" {
+		return d.FormattedMessage
+	}
+	if d.SourceLocation.File == "" {
+		return fmt.Sprintf("%s: %s", d.Type, d.Message)
+	}
+	return fmt.Sprintf("%s:%d: %s: %s", d.SourceLocation.File, d.SourceLocation.Start, d.Type, d.Message)
+}
+
+// SourceLocation is a range of bytes in a source file.
+type SourceLocation struct {
 	File  string `json:"file"`
 	Start int    `json:"start"`
 	End   int    `json:"end"`
 }
 
-type srcOut struct {
-	ID        int             `json:"id"`
-	AST       json.RawMessage `json:"ast"`
-	LegacyAST json.RawMessage `json:"legacyAST"`
+// SourceOutput is the output of solc for a source file.
+type SourceOutput struct {
+	ID        int             `json:"id"`        // Source file ID, as referenced in source maps.
+	AST       json.RawMessage `json:"ast"`       // Only set if "ast" is selected for the file, e.g. {"*": {"": {"ast"}}}.
+	LegacyAST json.RawMessage `json:"legacyAST"` // Only set by solc versions before 0.8.0 if "legacyAST" is selected.
+	Content   string          `json:"-"`         // Only set if [WithReturnSources] is set.
 }
 
+// Contract is the output of solc for a compiled contract. Fields are only set
+// if they are selected in the output selection.
 type Contract struct {
-	ABI      []json.RawMessage `json:"abi"`
-	Metadata string            `json:"metadata"`
-	UserDoc  json.RawMessage   `json:"userdoc"`
-	DevDoc   json.RawMessage   `json:"devdoc"`
-	IR       string            `json:"ir"`
-	EVM      evm               `json:"evm"`
+	ABI           []json.RawMessage `json:"abi"`
+	Metadata      string            `json:"metadata"` // Metadata JSON exactly as emitted by solc.
+	UserDoc       UserDoc           `json:"userdoc"`
+	DevDoc        DevDoc            `json:"devdoc"`
+	IR            string            `json:"ir"`            // Only set if "ir" is selected.
+	IROptimized   string            `json:"irOptimized"`   // Only set if "irOptimized" is selected.
+	StorageLayout *StorageLayout    `json:"storageLayout"` // Only set if "storageLayout" is selected.
+	EVM           EVM               `json:"evm"`
+
+	abi *abiCache // set when decoded, see [Contract.ParsedABI]
+}
+
+// UnmarshalJSON implements [json.Unmarshaler].
+func (c *Contract) UnmarshalJSON(data []byte) error {
+	type contract Contract
+	if err := json.Unmarshal(data, (*contract)(c)); err != nil {
+		return err
+	}
+	c.abi = new(abiCache)
+	return nil
+}
+
+// Deployable reports whether the contract has creation bytecode, i.e. it is
+// neither an interface nor an abstract contract. The bytecode must be selected
+// in the output selection, otherwise no contract is deployable.
+func (c Contract) Deployable() bool {
+	return len(c.EVM.Bytecode.Object) > 0
+}
+
+// CompilerVersion returns the long version of the compiler that compiled the
+// contract, e.g. "0.8.30+commit.73712a01", as recorded in its metadata. The
+// metadata must be selected in the output selection.
+func (c Contract) CompilerVersion() (string, error) {
+	if c.Metadata == "" {
+		return "", fmt.Errorf("solc: metadata not selected")
+	}
+
+	var metadata struct {
+		Compiler struct {
+			Version string `json:"version"`
+		} `json:"compiler"`
+	}
+	if err := json.Unmarshal([]byte(c.Metadata), &metadata); err != nil {
+		return "", fmt.Errorf("solc: invalid metadata: %w", err)
+	}
+	return metadata.Compiler.Version, nil
+}
+
+// abiCache caches the parsed ABI of a contract.
+type abiCache struct {
+	once   sync.Once
+	parsed abi.ABI
+	err    error
+}
+
+// ParsedABI returns the ABI of the contract parsed into go-ethereum's
+// [abi.ABI]. The ABI of a contract decoded from the compiler output is only
+// parsed once, so repeated calls are cheap. Each call returns a copy that may
+// be modified.
+func (c Contract) ParsedABI() (abi.ABI, error) {
+	if c.abi == nil {
+		return parseABI(c.ABI)
+	}
+
+	c.abi.once.Do(func() {
+		c.abi.parsed, c.abi.err = parseABI(c.ABI)
+	})
+	if c.abi.err != nil {
+		return abi.ABI{}, c.abi.err
+	}
+	parsed := c.abi.parsed
+	parsed.Methods = maps.Clone(parsed.Methods)
+	parsed.Events = maps.Clone(parsed.Events)
+	parsed.Errors = maps.Clone(parsed.Errors)
+	return parsed, nil
+}
+
+// parseABI parses the given ABI into go-ethereum's [abi.ABI].
+func parseABI(abiEntries []json.RawMessage) (abi.ABI, error) {
+	abiJSON, err := json.Marshal(abiOrEmpty(abiEntries))
+	if err != nil {
+		return abi.ABI{}, err
+	}
+
+	parsed, err := abi.JSON(bytes.NewReader(abiJSON))
+	if err != nil {
+		return abi.ABI{}, fmt.Errorf("solc: failed to parse ABI: %w", err)
+	}
+	return parsed, nil
+}
+
+// EventTopics returns the topic hashes (topic0) of the events in the ABI of
+// the contract, hex encoded without "0x" prefix and keyed by event signature,
+// e.g. "Transfer(address,address,uint256)". Anonymous events have no topic
+// hash and are omitted.
+func (c Contract) EventTopics() (map[string]string, error) {
+	parsed, err := c.ParsedABI()
+	if err != nil {
+		return nil, err
+	}
+	topics := make(map[string]string, len(parsed.Events))
+	for _, event := range parsed.Events {
+		if !event.Anonymous {
+			topics[event.Sig] = hex.EncodeToString(event.ID[:])
+		}
+	}
+	return topics, nil
+}
+
+// ErrorSelectors returns the 4 byte selectors of the custom errors in the ABI
+// of the contract, hex encoded without "0x" prefix like
+// [EVM.MethodIdentifiers] and keyed by error signature, e.g.
+// "InsufficientBalance(uint256,uint256)".
+func (c Contract) ErrorSelectors() (map[string]string, error) {
+	parsed, err := c.ParsedABI()
+	if err != nil {
+		return nil, err
+	}
+	selectors := make(map[string]string, len(parsed.Errors))
+	for _, e := range parsed.Errors {
+		selectors[e.Sig] = hex.EncodeToString(e.ID[:4])
+	}
+	return selectors, nil
+}
+
+// UserDoc is the NatSpec user documentation of a contract.
+type UserDoc struct {
+	Kind    string                   `json:"kind"`
+	Version int                      `json:"version"`
+	Notice  string                   `json:"notice"`
+	Methods map[string]UserDocItem   `json:"methods"` // Keyed by function signature.
+	Events  map[string]UserDocItem   `json:"events"`  // Keyed by event signature.
+	Errors  map[string][]UserDocItem `json:"errors"`  // Keyed by error signature.
+}
+
+// UserDocItem is the NatSpec user documentation of a function, event or error.
+type UserDocItem struct {
+	Notice string `json:"notice"`
+}
+
+func (item *UserDocItem) UnmarshalJSON(data []byte) error {
+	// older solc versions emit the notice of a constructor as plain string
+	if len(data) > 0 && data[0] == '"' {
+		return json.Unmarshal(data, &item.Notice)
+	}
+
+	type userDocItem UserDocItem
+	return json.Unmarshal(data, (*userDocItem)(item))
+}
+
+// DevDoc is the NatSpec developer documentation of a contract.
+type DevDoc struct {
+	Kind           string                  `json:"kind"`
+	Version        int                     `json:"version"`
+	Author         string                  `json:"author"`
+	Title          string                  `json:"title"`
+	Details        string                  `json:"details"`
+	Methods        map[string]DevDocItem   `json:"methods"`        // Keyed by function signature.
+	Events         map[string]DevDocItem   `json:"events"`         // Keyed by event signature.
+	Errors         map[string][]DevDocItem `json:"errors"`         // Keyed by error signature.
+	StateVariables map[string]DevDocItem   `json:"stateVariables"` // Keyed by variable name.
 }
 
-type evm struct {
-	Assembly          string                       `json:"assembly"`
-	LegacyAssembly    json.RawMessage              `json:"legacyAssembly"`
-	Bytecode          bytecode                     `json:"bytecode"`
-	DeployedBytecode  bytecode                     `json:"deployedBytecode"`
-	MethodIdentifiers map[string]string            `json:"methodIdentifiers"`
-	GasEstimates      map[string]map[string]string `json:"gasEstimates"`
+// DevDocItem is the NatSpec developer documentation of a function, event,
+// error or state variable.
+type DevDocItem struct {
+	Details string            `json:"details"`
+	Params  map[string]string `json:"params"`
+	Returns map[string]string `json:"returns"`
 }
 
-type bytecode struct {
-	Object         hexBytes                              `json:"object"`
-	Opcodes        string                                `json:"opcodes"`
-	SourceMap      string                                `json:"sourceMap"`
-	LinkReferences map[string]map[string][]linkReference `json:"linkReferences"`
+// StorageLayout describes the layout of the state variables of a contract in
+// storage.
+type StorageLayout struct {
+	Storage []StorageSlot          `json:"storage"`
+	Types   map[string]StorageType `json:"types"`
+}
+
+// StorageSlot describes the location of a state variable or struct member in
+// storage.
+type StorageSlot struct {
+	AstID    int    `json:"astId"`
+	Contract string `json:"contract"`
+	Label    string `json:"label"`
+	Offset   int    `json:"offset"` // Offset in bytes within the slot.
+	Slot     string `json:"slot"`   // Slot as decimal string.
+	Type     string `json:"type"`   // Key into [StorageLayout.Types].
+}
+
+// StorageType describes a type used in the [StorageLayout].
+type StorageType struct {
+	Encoding      string        `json:"encoding"`
+	Label         string        `json:"label"`
+	NumberOfBytes string        `json:"numberOfBytes"`
+	Base          string        `json:"base,omitempty"`
+	Key           string        `json:"key,omitempty"`
+	Value         string        `json:"value,omitempty"`
+	Members       []StorageSlot `json:"members,omitempty"`
+}
+
+// EVM is the EVM related output of a compiled contract.
+type EVM struct {
+	Assembly          string            `json:"assembly"`       // Only set if "evm.assembly" is selected.
+	LegacyAssembly    json.RawMessage   `json:"legacyAssembly"` // Only set if "evm.legacyAssembly" is selected.
+	Bytecode          Bytecode          `json:"bytecode"`
+	DeployedBytecode  Bytecode          `json:"deployedBytecode"`
+	MethodIdentifiers map[string]string `json:"methodIdentifiers"` // Maps canonical function signatures to hex selectors, e.g. "set(uint256)" to "60fe47b1".
+	GasEstimates      *GasEstimates     `json:"gasEstimates"`      // Only set if "evm.gasEstimates" is selected.
+}
+
+// Bytes returns the decoded creation bytecode (see [Bytecode.Bytes]).
+func (e EVM) Bytes() ([]byte, error) { return e.Bytecode.Bytes() }
+
+// DeployedBytes returns the decoded deployed bytecode (see [Bytecode.Bytes]).
+func (e EVM) DeployedBytes() ([]byte, error) { return e.DeployedBytecode.Bytes() }
+
+// GasEstimates are the gas estimates solc computes for the creation of a
+// contract and its functions.
+type GasEstimates struct {
+	Creation CreationGasEstimates `json:"creation"`
+	External map[string]Gas       `json:"external"` // Keyed by function signature.
+	Internal map[string]Gas       `json:"internal"` // Keyed by function signature.
+}
+
+// CreationGasEstimates are the gas estimates for the creation of a contract.
+type CreationGasEstimates struct {
+	CodeDepositCost Gas `json:"codeDepositCost"`
+	ExecutionCost   Gas `json:"executionCost"`
+	TotalCost       Gas `json:"totalCost"`
+}
+
+// Gas is a gas estimate, which is either a finite amount of gas or infinite.
+type Gas struct {
+	Value    uint64
+	Infinite bool
+}
+
+func (g Gas) String() string {
+	if g.Infinite {
+		return "infinite"
+	}
+	return strconv.FormatUint(g.Value, 10)
+}
+
+func (g Gas) MarshalText() ([]byte, error) {
+	return []byte(g.String()), nil
+}
+
+func (g *Gas) UnmarshalText(text []byte) error {
+	if string(text) == "infinite" {
+		*g = Gas{Infinite: true}
+		return nil
+	}
+
+	val, err := strconv.ParseUint(string(text), 10, 64)
+	if err != nil {
+		return fmt.Errorf("solc: invalid gas estimate %q", text)
+	}
+	*g = Gas{Value: val}
+	return nil
+}
+
+// Bytecode is the creation or deployed bytecode of a compiled contract.
+type Bytecode struct {
+	Object         hexBytes                        `json:"object"` // Placeholders of unlinked libraries are zero bytes.
+	Opcodes        string                          `json:"opcodes"`
+	SourceMap      string                          `json:"sourceMap"`      // Compressed source map in solc's "s:l:f:j:m" format.
+	LinkReferences map[string]map[string][]LinkRef `json:"linkReferences"` // Unlinked libraries keyed by source file and library name.
+
+	// ImmutableReferences are the locations of immutable variables in the
+	// deployed bytecode, keyed by the AST ID of the variable declaration. Only
+	// set for the deployed bytecode if "evm.deployedBytecode.immutableReferences"
+	// is selected.
+	ImmutableReferences map[string][]LinkRef `json:"immutableReferences"`
+}
+
+// UnmarshalJSON decodes the bytecode from solc's output. The placeholders of
+// unlinked libraries in the hex encoded object are decoded as zero bytes, their
+// positions are given by the link references.
+func (b *Bytecode) UnmarshalJSON(data []byte) error {
+	type bytecode Bytecode // without methods
+	dec := struct {
+		*bytecode
+		Object string `json:"object"`
+	}{bytecode: (*bytecode)(b)}
+	if err := json.Unmarshal(data, &dec); err != nil {
+		return err
+	}
+
+	obj := placeholderRegexp.ReplaceAllLiteralString(dec.Object, strings.Repeat("0", 40))
+	return b.Object.UnmarshalText([]byte(obj))
+}
+
+// Bytes returns the bytecode. An error is returned if the bytecode references
+// unlinked libraries, see [Bytecode.Link].
+func (b Bytecode) Bytes() ([]byte, error) {
+	if libs := b.unlinkedLibraries(); len(libs) > 0 {
+		return nil, fmt.Errorf("solc: bytecode contains unlinked libraries: %s", strings.Join(libs, ", "))
+	}
+	return b.Object, nil
+}
+
+// unlinkedLibraries returns the sorted fully qualified names of the libraries
+// referenced by the link references of the bytecode.
+func (b Bytecode) unlinkedLibraries() []string {
+	var libs []string
+	for _, file := range slices.Sorted(maps.Keys(b.LinkReferences)) {
+		for _, lib := range slices.Sorted(maps.Keys(b.LinkReferences[file])) {
+			libs = append(libs, file+":"+lib)
+		}
+	}
+	return libs
+}
+
+// hex returns the hex encoded bytecode as reported by solc, i.e. with the
+// placeholders of unlinked libraries.
+func (b Bytecode) hex() string {
+	obj := []byte(hex.EncodeToString(b.Object))
+	for file, libs := range b.LinkReferences {
+		for lib, refs := range libs {
+			placeholder := libraryPlaceholder(file + ":" + lib)
+			for _, ref := range refs {
+				if start := 2 * ref.Start; ref.Length == 20 && start+len(placeholder) <= len(obj) {
+					copy(obj[start:], placeholder)
+				}
+			}
+		}
+	}
+	return string(obj)
 }
 
-type linkReference struct {
-	Start int `json:"start"`
-	End   int `json:"end"`
+// LinkRef is the position of a reference in the bytecode, e.g. of an unlinked
+// library address.
+type LinkRef struct {
+	Start  int `json:"start"`  // Offset in bytes.
+	Length int `json:"length"` // Length in bytes.
 }
 
 // hexBytes is a byte slice that is unmarshalled from a hexstring.
@@ -0,0 +1,573 @@
+package solc
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/google/go-cmp/cmp"
+	"github.com/google/go-cmp/cmp/cmpopts"
+)
+
+func TestContractStorageLayout(t *testing.T) {
+	data := []byte(`{
+		"storageLayout": {
+			"storage": [
+				{"astId": 3, "contract": "Test.sol:Test", "label": "owner", "offset": 0, "slot": "0", "type": "t_address"},
+				{"astId": 5, "contract": "Test.sol:Test", "label": "paused", "offset": 20, "slot": "0", "type": "t_bool"},
+				{"astId": 9, "contract": "Test.sol:Test", "label": "balances", "offset": 0, "slot": "1", "type": "t_mapping(t_address,t_uint256)"}
+			],
+			"types": {
+				"t_address": {"encoding": "inplace", "label": "address", "numberOfBytes": "20"},
+				"t_bool": {"encoding": "inplace", "label": "bool", "numberOfBytes": "1"},
+				"t_mapping(t_address,t_uint256)": {"encoding": "mapping", "key": "t_address", "label": "mapping(address => uint256)", "numberOfBytes": "32", "value": "t_uint256"},
+				"t_uint256": {"encoding": "inplace", "label": "uint256", "numberOfBytes": "32"}
+			}
+		}
+	}`)
+
+	var contract Contract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		t.Fatalf("Failed to unmarshal contract: %v", err)
+	}
+
+	want := &StorageLayout{
+		Storage: []StorageSlot{
+			{AstID: 3, Contract: "Test.sol:Test", Label: "owner", Offset: 0, Slot: "0", Type: "t_address"},
+			{AstID: 5, Contract: "Test.sol:Test", Label: "paused", Offset: 20, Slot: "0", Type: "t_bool"},
+			{AstID: 9, Contract: "Test.sol:Test", Label: "balances", Offset: 0, Slot: "1", Type: "t_mapping(t_address,t_uint256)"},
+		},
+		Types: map[string]StorageType{
+			"t_address": {Encoding: "inplace", Label: "address", NumberOfBytes: "20"},
+			"t_bool":    {Encoding: "inplace", Label: "bool", NumberOfBytes: "1"},
+			"t_mapping(t_address,t_uint256)": {
+				Encoding: "mapping", Label: "mapping(address => uint256)", NumberOfBytes: "32",
+				Key: "t_address", Value: "t_uint256",
+			},
+			"t_uint256": {Encoding: "inplace", Label: "uint256", NumberOfBytes: "32"},
+		},
+	}
+	if diff := cmp.Diff(want, contract.StorageLayout); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
+
+func TestContractStorageLayoutNotSelected(t *testing.T) {
+	var contract Contract
+	if err := json.Unmarshal([]byte(`{"abi": []}`), &contract); err != nil {
+		t.Fatalf("Failed to unmarshal contract: %v", err)
+	}
+	if contract.StorageLayout != nil {
+		t.Fatalf("want nil storage layout, got %+v", contract.StorageLayout)
+	}
+}
+
+func TestContractGasEstimates(t *testing.T) {
+	data := []byte(`{
+		"evm": {
+			"gasEstimates": {
+				"creation": {"codeDepositCost": "93800", "executionCost": "144", "totalCost": "93944"},
+				"external": {"get()": "2459", "set(uint256)": "infinite"},
+				"internal": {"_set(uint256)": "infinite"}
+			}
+		}
+	}`)
+
+	var contract Contract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		t.Fatalf("Failed to unmarshal contract: %v", err)
+	}
+
+	want := &GasEstimates{
+		Creation: CreationGasEstimates{
+			CodeDepositCost: Gas{Value: 93800},
+			ExecutionCost:   Gas{Value: 144},
+			TotalCost:       Gas{Value: 93944},
+		},
+		External: map[string]Gas{
+			"get()":        {Value: 2459},
+			"set(uint256)": {Infinite: true},
+		},
+		Internal: map[string]Gas{
+			"_set(uint256)": {Infinite: true},
+		},
+	}
+	if diff := cmp.Diff(want, contract.EVM.GasEstimates); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
+
+func TestGasUnmarshalText(t *testing.T) {
+	var gas Gas
+	if err := gas.UnmarshalText([]byte("-1")); err == nil {
+		t.Fatal("want error for invalid gas estimate")
+	}
+}
+
+func TestContractMethodIdentifiers(t *testing.T) {
+	data := []byte(`{
+		"evm": {
+			"methodIdentifiers": {"get()": "6d4ce63c", "set(uint256)": "60fe47b1"}
+		}
+	}`)
+
+	var contract Contract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		t.Fatalf("Failed to unmarshal contract: %v", err)
+	}
+
+	want := map[string]string{
+		"get()":        "6d4ce63c",
+		"set(uint256)": "60fe47b1",
+	}
+	if diff := cmp.Diff(want, contract.EVM.MethodIdentifiers); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
+
+func TestContractNatSpec(t *testing.T) {
+	data := []byte(`{
+		"userdoc": {
+			"kind": "user",
+			"version": 1,
+			"notice": "Stores a value.",
+			"methods": {
+				"constructor": "Creates the store.",
+				"set(uint256)": {"notice": "Sets the value."}
+			}
+		},
+		"devdoc": {
+			"kind": "dev",
+			"version": 1,
+			"author": "Alice",
+			"title": "Store",
+			"methods": {
+				"get()": {"returns": {"_0": "The value."}},
+				"set(uint256)": {"details": "Emits no event.", "params": {"x": "The new value."}}
+			}
+		}
+	}`)
+
+	var contract Contract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		t.Fatalf("Failed to unmarshal contract: %v", err)
+	}
+
+	wantUserDoc := UserDoc{
+		Kind:    "user",
+		Version: 1,
+		Notice:  "Stores a value.",
+		Methods: map[string]UserDocItem{
+			"constructor":  {Notice: "Creates the store."},
+			"set(uint256)": {Notice: "Sets the value."},
+		},
+	}
+	if diff := cmp.Diff(wantUserDoc, contract.UserDoc); diff != "" {
+		t.Fatalf("UserDoc (-want +got)\n%s", diff)
+	}
+
+	wantDevDoc := DevDoc{
+		Kind:    "dev",
+		Version: 1,
+		Author:  "Alice",
+		Title:   "Store",
+		Methods: map[string]DevDocItem{
+			"get()":        {Returns: map[string]string{"_0": "The value."}},
+			"set(uint256)": {Details: "Emits no event.", Params: map[string]string{"x": "The new value."}},
+		},
+	}
+	if diff := cmp.Diff(wantDevDoc, contract.DevDoc); diff != "" {
+		t.Fatalf("DevDoc (-want +got)\n%s", diff)
+	}
+}
+
+func TestContractNoNatSpec(t *testing.T) {
+	var contract Contract
+	if err := json.Unmarshal([]byte(`{"userdoc": {}, "devdoc": null}`), &contract); err != nil {
+		t.Fatalf("Failed to unmarshal contract: %v", err)
+	}
+	if diff := cmp.Diff(UserDoc{}, contract.UserDoc); diff != "" {
+		t.Fatalf("UserDoc (-want +got)\n%s", diff)
+	}
+	if diff := cmp.Diff(DevDoc{}, contract.DevDoc); diff != "" {
+		t.Fatalf("DevDoc (-want +got)\n%s", diff)
+	}
+}
+
+func TestContractSourceMap(t *testing.T) {
+	data := []byte(`{
+		"evm": {
+			"bytecode": {"object": "6080", "opcodes": "PUSH1 0x80", "sourceMap": "26:71:0:-:0;;;;;;;"},
+			"deployedBytecode": {"object": "6080", "opcodes": "PUSH1 0x80", "sourceMap": "26:71:0:-:0;;;;;;;;;;;;;;;;;"}
+		}
+	}`)
+
+	var contract Contract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		t.Fatalf("Failed to unmarshal contract: %v", err)
+	}
+
+	if want := "26:71:0:-:0;;;;;;;"; want != contract.EVM.Bytecode.SourceMap {
+		t.Fatalf("want source map %q, got %q", want, contract.EVM.Bytecode.SourceMap)
+	}
+	if want := "26:71:0:-:0;;;;;;;;;;;;;;;;;"; want != contract.EVM.DeployedBytecode.SourceMap {
+		t.Fatalf("want deployed source map %q, got %q", want, contract.EVM.DeployedBytecode.SourceMap)
+	}
+	if want := "PUSH1 0x80"; want != contract.EVM.Bytecode.Opcodes {
+		t.Fatalf("want opcodes %q, got %q", want, contract.EVM.Bytecode.Opcodes)
+	}
+}
+
+func TestContractLinkReferences(t *testing.T) {
+	object := "73" + libraryPlaceholder("Lib.sol:Lib") + "6000"
+	data := []byte(`{
+		"evm": {
+			"bytecode": {
+				"object": "` + object + `",
+				"linkReferences": {"Lib.sol": {"Lib": [{"start": 1, "length": 20}]}}
+			},
+			"deployedBytecode": {
+				"object": "` + object + `",
+				"linkReferences": {"Lib.sol": {"Lib": [{"start": 1, "length": 20}]}}
+			}
+		}
+	}`)
+
+	var contract Contract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		t.Fatalf("Failed to unmarshal contract: %v", err)
+	}
+
+	want := map[string]map[string][]LinkRef{
+		"Lib.sol": {"Lib": {{Start: 1, Length: 20}}},
+	}
+	if diff := cmp.Diff(want, contract.EVM.Bytecode.LinkReferences); diff != "" {
+		t.Fatalf("Bytecode (-want +got)\n%s", diff)
+	}
+	if diff := cmp.Diff(want, contract.EVM.DeployedBytecode.LinkReferences); diff != "" {
+		t.Fatalf("DeployedBytecode (-want +got)\n%s", diff)
+	}
+
+	// placeholders are decoded as zero bytes and restored in the hex encoding
+	wantObject := append(append([]byte{0x73}, make([]byte, 20)...), 0x60, 0x00)
+	if diff := cmp.Diff(wantObject, []byte(contract.EVM.Bytecode.Object)); diff != "" {
+		t.Fatalf("Object (-want +got)\n%s", diff)
+	}
+	if got := contract.EVM.Bytecode.hex(); got != object {
+		t.Fatalf("want hex %q, got %q", object, got)
+	}
+}
+
+func TestContractImmutableReferences(t *testing.T) {
+	data := []byte(`{
+		"evm": {
+			"deployedBytecode": {
+				"object": "6080",
+				"immutableReferences": {"3": [{"start": 120, "length": 32}, {"start": 210, "length": 32}]}
+			}
+		}
+	}`)
+
+	var contract Contract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		t.Fatalf("Failed to unmarshal contract: %v", err)
+	}
+
+	want := map[string][]LinkRef{"3": {{Start: 120, Length: 32}, {Start: 210, Length: 32}}}
+	if diff := cmp.Diff(want, contract.EVM.DeployedBytecode.ImmutableReferences); diff != "" {
+		t.Fatalf("(-want +got)\n%s", diff)
+	}
+}
+
+func TestContractMetadata(t *testing.T) {
+	metadata := `{"compiler":{"version":"0.8.30+commit.73712a01"},"language":"Solidity","output":{},"settings":{},"sources":{},"version":1}`
+	data, err := json.Marshal(map[string]string{"metadata": metadata})
+	if err != nil {
+		t.Fatalf("Failed to marshal contract: %v", err)
+	}
+
+	var contract Contract
+	if err := json.Unmarshal(data, &contract); err != nil {
+		t.Fatalf("Failed to unmarshal contract: %v", err)
+	}
+	if metadata != contract.Metadata {
+		t.Fatalf("want metadata %s, got %s", metadata, contract.Metadata)
+	}
+}
+
+func TestDiagnosticString(t *testing.T) {
+	tests := []struct {
+		Diag Diagnostic
+		Want string
+	}{
+		{
+			Diag: Diagnostic{Type: "TypeError", Message: "Type mismatch.", FormattedMessage: "TypeError: Type mismatch.\n --> Test.sol:1:1:\n"},
+			Want: "TypeError: Type mismatch.\n --> Test.sol:1:1:\n",
+		},
+		{
+			Diag: Diagnostic{SourceLocation: SourceLocation{File: "Test.sol", Start: 10, End: 20}, Type: "TypeError", Message: "Type mismatch."},
+			Want: "Test.sol:10: TypeError: Type mismatch.",
+		},
+		{
+			Diag: Diagnostic{Type: "JSONError", Message: "Invalid input."},
+			Want: "JSONError: Invalid input.",
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := test.Diag.String(); got != test.Want {
+				t.Fatalf("want %q, got %q", test.Want, got)
+			}
+		})
+	}
+}
+
+func TestContractParsedABI(t *testing.T) {
+	var c Contract
+	if err := json.Unmarshal([]byte(`{"abi": [
+		{"type":"function","name":"set","inputs":[{"name":"x","type":"uint256"}],"outputs":[],"stateMutability":"nonpayable"}
+	]}`), &c); err != nil {
+		t.Fatal(err)
+	}
+
+	parsed, err := c.ParsedABI()
+	if err != nil {
+		t.Fatalf("Failed to parse ABI: %v", err)
+	}
+	method, ok := parsed.Methods["set"]
+	if !ok {
+		t.Fatal("want method set")
+	}
+	if want := "60fe47b1"; fmt.Sprintf("%x", method.ID) != want {
+		t.Fatalf("want method ID %s, got %x", want, method.ID)
+	}
+
+	// repeated calls return independent ABIs
+	delete(parsed.Methods, "set")
+	parsed2, err := c.ParsedABI()
+	if err != nil {
+		t.Fatalf("Failed to parse ABI: %v", err)
+	}
+	if parsed2.Methods["set"].Sig != method.Sig {
+		t.Fatalf("want unmodified ABI, got %v", parsed2)
+	}
+
+	// the ABI is only parsed once
+	c.ABI = []json.RawMessage{json.RawMessage(`1`)}
+	if _, err := c.ParsedABI(); err != nil {
+		t.Fatalf("want cached ABI, got %v", err)
+	}
+
+	// invalid ABIs return an error
+	c = Contract{ABI: []json.RawMessage{json.RawMessage(`1`)}}
+	if _, err := c.ParsedABI(); err == nil {
+		t.Fatal("want error for invalid ABI")
+	}
+}
+
+func TestBytecodeBytes(t *testing.T) {
+	tests := []struct {
+		Bytecode Bytecode
+		Want     []byte
+		WantErr  bool
+	}{
+		{Bytecode: Bytecode{}, Want: nil},
+		{Bytecode: Bytecode{Object: hexBytes{0x60, 0x80}}, Want: []byte{0x60, 0x80}},
+		{
+			Bytecode: Bytecode{
+				Object:         make(hexBytes, 23),
+				LinkReferences: map[string]map[string][]LinkRef{"L.sol": {"L": {{Start: 1, Length: 20}}}},
+			},
+			WantErr: true,
+		},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := test.Bytecode.Bytes()
+			if test.WantErr {
+				if err == nil {
+					t.Fatal("want error, got nil")
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("Failed to decode bytecode: %v", err)
+			}
+			if diff := cmp.Diff(test.Want, got); diff != "" {
+				t.Fatalf("(-want +got)\n%s", diff)
+			}
+		})
+	}
+}
+
+func TestEVMBytes(t *testing.T) {
+	e := EVM{
+		Bytecode:         Bytecode{Object: hexBytes{0x60, 0x80}},
+		DeployedBytecode: Bytecode{Object: hexBytes{0x60, 0x01}},
+	}
+
+	code, err := e.Bytes()
+	if err != nil || !bytes.Equal(code, []byte{0x60, 0x80}) {
+		t.Fatalf("want 0x6080, got %x (%v)", code, err)
+	}
+	deployedCode, err := e.DeployedBytes()
+	if err != nil || !bytes.Equal(deployedCode, []byte{0x60, 0x01}) {
+		t.Fatalf("want 0x6001, got %x (%v)", deployedCode, err)
+	}
+}
+
+func TestContractCompilerVersion(t *testing.T) {
+	c := Contract{Metadata: `{"compiler":{"version":"0.8.30+commit.73712a01"},"language":"Solidity"}`}
+	got, err := c.CompilerVersion()
+	if err != nil {
+		t.Fatalf("Failed to get compiler version: %v", err)
+	}
+	if want := "0.8.30+commit.73712a01"; got != want {
+		t.Fatalf("want %q, got %q", want, got)
+	}
+
+	if _, err := (Contract{}).CompilerVersion(); err == nil {
+		t.Fatal("want error without metadata")
+	}
+}
+
+func TestContractIR(t *testing.T) {
+	data := []byte(`{"ir": "object \"Test\" {}", "irOptimized": "object \"Test\" { code {} }"}`)
+
+	var got Contract
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to decode contract: %v", err)
+	}
+	if got.IR != `object "Test" {}` || got.IROptimized != `object "Test" { code {} }` {
+		t.Fatalf("want IR and optimized IR, got %q and %q", got.IR, got.IROptimized)
+	}
+}
+
+func TestContractAssembly(t *testing.T) {
+	data := []byte(`{"evm": {
+		"assembly": "    /* \"Test.sol\":0:16  contract Test {} */\n  mstore(0x40, 0x80)\n",
+		"legacyAssembly": {".code": [{"begin": 0, "end": 16, "name": "PUSH", "value": "80"}]}
+	}}`)
+
+	var got Contract
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatalf("Failed to decode contract: %v", err)
+	}
+	if !strings.Contains(got.EVM.Assembly, "mstore(0x40, 0x80)") {
+		t.Fatalf("want assembly, got %q", got.EVM.Assembly)
+	}
+
+	var legacy map[string]any
+	if err := json.Unmarshal(got.EVM.LegacyAssembly, &legacy); err != nil {
+		t.Fatalf("Failed to decode legacy assembly: %v", err)
+	}
+	if _, ok := legacy[".code"]; !ok {
+		t.Fatalf("want legacy assembly code, got %v", legacy)
+	}
+}
+
+func TestOutputNormalizePaths(t *testing.T) {
+	root := filepath.FromSlash("/work/contracts")
+	out := &Output{
+		Errors: []Diagnostic{{SourceLocation: SourceLocation{File: "/work/contracts/lib/Lib.sol"}}},
+		Sources: map[string]SourceOutput{
+			"Test.sol":                    {ID: 0},
+			"/work/contracts/lib/Lib.sol": {ID: 1},
+			"/work/other/Other.sol":       {ID: 2},
+		},
+		Contracts: map[string]map[string]Contract{
+			"Test.sol":                    {"Test": {}},
+			"/work/contracts/lib/Lib.sol": {"Lib": {}},
+			"/work/contracts/Test.sol":    {"Test2": {}}, // would collide
+		},
+	}
+	out.normalizePaths(root)
+
+	wantSources := map[string]SourceOutput{
+		"Test.sol":              {ID: 0},
+		"lib/Lib.sol":           {ID: 1},
+		"/work/other/Other.sol": {ID: 2},
+	}
+	if diff := cmp.Diff(wantSources, out.Sources); diff != "" {
+		t.Fatalf("Sources (-want +got)\n%s", diff)
+	}
+	wantContracts := map[string]map[string]Contract{
+		"Test.sol":                 {"Test": {}},
+		"lib/Lib.sol":              {"Lib": {}},
+		"/work/contracts/Test.sol": {"Test2": {}},
+	}
+	if diff := cmp.Diff(wantContracts, out.Contracts, cmpopts.IgnoreUnexported(Contract{})); diff != "" {
+		t.Fatalf("Contracts (-want +got)\n%s", diff)
+	}
+	if got := out.Errors[0].SourceLocation.File; got != "lib/Lib.sol" {
+		t.Fatalf("want diagnostic file %q, got %q", "lib/Lib.sol", got)
+	}
+}
+
+func TestCompileErrorFirstErrorStrictWarnings(t *testing.T) {
+	err := &CompileError{Diagnostics: []Diagnostic{{Severity: "warning", Type: "Warning"}}}
+	if first := err.FirstError(); first != nil {
+		t.Fatalf("want no first error, got %v", first)
+	}
+	if got := len(err.Warnings()); got != 1 {
+		t.Fatalf("want 1 warning, got %d", got)
+	}
+}
+
+func TestContractDeployable(t *testing.T) {
+	tests := []struct {
+		Contract Contract
+		Want     bool
+	}{
+		{Contract: Contract{EVM: EVM{Bytecode: Bytecode{Object: hexBytes{0x60, 0x80}}}}, Want: true},
+		{Contract: Contract{EVM: EVM{Bytecode: Bytecode{}}}, Want: false},                   // interface or abstract
+		{Contract: Contract{EVM: EVM{Bytecode: Bytecode{Object: hexBytes{}}}}, Want: false}, // interface or abstract
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			if got := test.Contract.Deployable(); test.Want != got {
+				t.Fatalf("want %t, got %t", test.Want, got)
+			}
+		})
+	}
+}
+
+func TestContractEventTopicsErrorSelectors(t *testing.T) {
+	c := Contract{ABI: []json.RawMessage{
+		json.RawMessage(`{"type":"event","name":"Transfer","anonymous":false,"inputs":[` +
+			`{"name":"from","type":"address","indexed":true},{"name":"to","type":"address","indexed":true},` +
+			`{"name":"value","type":"uint256","indexed":false}]}`),
+		json.RawMessage(`{"type":"event","name":"Anon","anonymous":true,"inputs":[]}`),
+		json.RawMessage(`{"type":"error","name":"InsufficientBalance","inputs":[` +
+			`{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}`),
+	}}
+
+	topics, err := c.EventTopics()
+	if err != nil {
+		t.Fatalf("Failed to compute event topics: %v", err)
+	}
+	wantTopics := map[string]string{
+		"Transfer(address,address,uint256)": "ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+	}
+	if diff := cmp.Diff(wantTopics, topics); diff != "" {
+		t.Fatalf("Topics (-want +got)\n%s", diff)
+	}
+
+	selectors, err := c.ErrorSelectors()
+	if err != nil {
+		t.Fatalf("Failed to compute error selectors: %v", err)
+	}
+	wantSelectors := map[string]string{
+		"InsufficientBalance(uint256,uint256)": "cf479181",
+	}
+	if diff := cmp.Diff(wantSelectors, selectors); diff != "" {
+		t.Fatalf("Selectors (-want +got)\n%s", diff)
+	}
+}
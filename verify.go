@@ -0,0 +1,115 @@
+package solc
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+)
+
+// BytecodeMismatchError is returned by [VerifyDeployed] if the on-chain
+// bytecode differs from the compiled bytecode.
+type BytecodeMismatchError struct {
+	Offset   int    // Offset of the first differing byte.
+	Want     []byte // Compiled bytecode, without metadata if it was ignored.
+	Got      []byte // On-chain bytecode, without metadata if it was ignored.
+	Metadata bool   // Whether the metadata of the compiled bytecode was ignored.
+}
+
+func (e *BytecodeMismatchError) Error() string {
+	if len(e.Want) != len(e.Got) {
+		return fmt.Sprintf("solc: bytecode mismatch: want %d bytes, got %d bytes (first difference at offset %d)",
+			len(e.Want), len(e.Got), e.Offset)
+	}
+	return fmt.Sprintf("solc: bytecode mismatch at offset %d: want %#02x, got %#02x",
+		e.Offset, e.Want[e.Offset], e.Got[e.Offset])
+}
+
+// VerifyOption configures [VerifyDeployed].
+type VerifyOption func(*verifyOptions)
+
+type verifyOptions struct {
+	withMetadata bool
+}
+
+// VerifyWithMetadata configures [VerifyDeployed] to compare the CBOR encoded
+// metadata as well, i.e. to require an exact match including the metadata
+// hash.
+func VerifyWithMetadata() VerifyOption {
+	return func(o *verifyOptions) {
+		o.withMetadata = true
+	}
+}
+
+// VerifyDeployed reports whether the given on-chain runtime bytecode matches
+// the deployed bytecode of the given compiled contract. The CBOR encoded
+// metadata appended by solc is ignored unless [VerifyWithMetadata] is given, as
+// it depends on details such as comments in the source files. The locations of
+// immutable variables are always ignored, as their values are only known after
+// deployment.
+//
+// The contract must have been compiled with the deployed bytecode selected and
+// its libraries linked, see [Bytecode.Link]. The immutable references
+// ([ArtifactImmutableReferences]) must be selected if the contract has
+// immutable variables. If the bytecode differs, false is returned together
+// with a [*BytecodeMismatchError] describing the first difference.
+func VerifyDeployed(onchain []byte, result Contract, opts ...VerifyOption) (bool, error) {
+	var o verifyOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+
+	compiled, err := result.EVM.DeployedBytecode.Bytes()
+	if err != nil {
+		return false, err
+	}
+	if len(compiled) == 0 {
+		return false, fmt.Errorf("solc: contract has no deployed bytecode")
+	}
+
+	// zero the immutable variables in both bytecodes
+	want, got := bytes.Clone(compiled), bytes.Clone(onchain)
+	for id, refs := range result.EVM.DeployedBytecode.ImmutableReferences {
+		for _, ref := range refs {
+			if ref.Start < 0 || ref.Length < 0 || ref.Start+ref.Length > len(want) {
+				return false, fmt.Errorf("solc: invalid immutable reference of %s", id)
+			}
+			clear(want[ref.Start : ref.Start+ref.Length])
+			if ref.Start+ref.Length <= len(got) {
+				clear(got[ref.Start : ref.Start+ref.Length])
+			}
+		}
+	}
+
+	// strip the metadata
+	wantCode, gotCode, metadata := want, got, false
+	if !o.withMetadata {
+		if wantCode, metadata = stripMetadata(want); metadata {
+			gotCode, _ = stripMetadata(got)
+		}
+	}
+
+	if bytes.Equal(wantCode, gotCode) {
+		return true, nil
+	}
+	offset := 0
+	for offset < min(len(wantCode), len(gotCode)) && wantCode[offset] == gotCode[offset] {
+		offset++
+	}
+	return false, &BytecodeMismatchError{Offset: offset, Want: wantCode, Got: gotCode, Metadata: metadata}
+}
+
+// stripMetadata returns the bytecode without the CBOR encoded metadata that
+// solc appends to it, and whether the bytecode contained metadata. The metadata
+// is followed by its length encoded as 2 byte big endian integer.
+func stripMetadata(code []byte) ([]byte, bool) {
+	if len(code) < 2 {
+		return code, false
+	}
+	n := int(binary.BigEndian.Uint16(code[len(code)-2:]))
+	start := len(code) - 2 - n
+	// the metadata is a CBOR map with 1 to 5 entries
+	if n == 0 || start < 0 || code[start] < 0xa1 || code[start] > 0xa5 {
+		return code, false
+	}
+	return code[:start], true
+}
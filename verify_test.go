@@ -0,0 +1,77 @@
+package solc
+
+import (
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"testing"
+)
+
+func TestVerifyDeployed(t *testing.T) {
+	// 2 byte immutable at offset 3 (instead of 32 bytes for brevity), followed
+	// by the metadata {"solc": 0x000100} and its length
+	const (
+		code     = "60807f0000"
+		metadata = "a164736f6c6343000100" + "000a"
+	)
+	contract := Contract{EVM: EVM{DeployedBytecode: Bytecode{
+		Object:              mustDecodeHex(t, code+metadata),
+		ImmutableReferences: map[string][]LinkRef{"3": {{Start: 3, Length: 2}}},
+	}}}
+
+	tests := []struct {
+		Onchain    string
+		Opts       []VerifyOption
+		Want       bool
+		WantOffset int
+	}{
+		{Onchain: code + metadata, Want: true},
+		{Onchain: "60807fc0de" + metadata, Want: true},                // immutable value set
+		{Onchain: code + "a164736f6c6343000200" + "000a", Want: true}, // different metadata
+		{Onchain: code, Want: true},                                   // no metadata
+		{Onchain: "60817fc0de" + metadata, WantOffset: 1},
+		{Onchain: "60807fc0de00" + metadata, WantOffset: 5},
+		{Onchain: "60807fc0de" + metadata, Opts: []VerifyOption{VerifyWithMetadata()}, Want: true},
+		{Onchain: code + "a164736f6c6343000200" + "000a", Opts: []VerifyOption{VerifyWithMetadata()}, WantOffset: 13},
+		{Onchain: code, Opts: []VerifyOption{VerifyWithMetadata()}, WantOffset: 5},
+	}
+
+	for i, test := range tests {
+		t.Run(strconv.Itoa(i), func(t *testing.T) {
+			got, err := VerifyDeployed(mustDecodeHex(t, test.Onchain), contract, test.Opts...)
+			if test.Want != got {
+				t.Fatalf("want %t, got %t (%v)", test.Want, got, err)
+			}
+			if test.Want {
+				if err != nil {
+					t.Fatalf("want no error, got %v", err)
+				}
+				return
+			}
+			var mismatch *BytecodeMismatchError
+			if !errors.As(err, &mismatch) || mismatch.Offset != test.WantOffset {
+				t.Fatalf("want mismatch at offset %d, got %v", test.WantOffset, err)
+			}
+		})
+	}
+
+	t.Run("unlinked", func(t *testing.T) {
+		unlinked := Contract{EVM: EVM{DeployedBytecode: Bytecode{
+			Object:         make(hexBytes, 21),
+			LinkReferences: map[string]map[string][]LinkRef{"L.sol": {"L": {{Start: 1, Length: 20}}}},
+		}}}
+		if ok, err := VerifyDeployed(nil, unlinked); ok || err == nil {
+			t.Fatalf("want error, got %t, %v", ok, err)
+		}
+	})
+}
+
+func mustDecodeHex(t *testing.T, s string) []byte {
+	t.Helper()
+
+	b, err := hex.DecodeString(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return b
+}